@@ -0,0 +1,109 @@
+package v1beta1
+
+import (
+	"github.com/fluxcd/pkg/apis/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AuraDatabaseUser is the Schema for the auradatabaseusers API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+type AuraDatabaseUser struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AuraDatabaseUserSpec   `json:"spec,omitempty"`
+	Status AuraDatabaseUserStatus `json:"status,omitempty"`
+}
+
+type AuraDatabaseUserSpec struct {
+	// InstanceRef references the AuraInstance in the same namespace the user is created on
+	// +kubebuilder:validation:Required
+	InstanceRef LocalObjectReference `json:"instanceRef"`
+
+	// Username of the database user, defaults to metadata.name.
+	// The admin user of the instance and the names of built-in roles are not allowed.
+	// +optional
+	Username string `json:"username,omitempty"`
+
+	// Roles granted to the user, e.g. reader, editor or publisher
+	// +optional
+	Roles []string `json:"roles,omitempty"`
+
+	// Privileges granted to the user using the role ${username}_privileges.
+	// Each entry is a graph or database privilege as used in GRANT <privilege> TO <role>, e.g. "MATCH {*} ON GRAPH neo4j".
+	// DBMS privileges, statement separators and comments are not allowed.
+	// +kubebuilder:validation:items:Pattern=`^[^;/]+$`
+	// +optional
+	Privileges []string `json:"privileges,omitempty"`
+
+	// Secret is a reference to the secret which will contain the user credentials.
+	// By default this will be ${metadataname}-credentials
+	// +optional
+	Secret LocalObjectReference `json:"secret,omitempty"`
+
+	// Suspend tells the controller to suspend reconciliation for this user
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+type AuraDatabaseUserStatus struct {
+	meta.ReconcileRequestStatus `json:",inline"`
+
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the last generation reconciled by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Username of the database user
+	// +optional
+	Username string `json:"username,omitempty"`
+
+	// Roles currently granted to the user
+	// +optional
+	Roles []string `json:"roles,omitempty"`
+
+	// Privileges currently granted to the role of the user
+	// +optional
+	Privileges []string `json:"privileges,omitempty"`
+
+	// Secret is the name of the secret which contains the user credentials
+	// +optional
+	Secret string `json:"secret,omitempty"`
+}
+
+// AuraDatabaseUserList contains a list of AuraDatabaseUser.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type AuraDatabaseUserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AuraDatabaseUser `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AuraDatabaseUser{}, &AuraDatabaseUserList{})
+}
+
+func AuraDatabaseUserReady(set AuraDatabaseUser, status metav1.ConditionStatus, reason, message string) AuraDatabaseUser {
+	setResourceCondition(&set, ConditionReady, status, reason, message, set.Generation)
+	return set
+}
+
+func AuraDatabaseUserStalled(set AuraDatabaseUser, status metav1.ConditionStatus, reason, message string) AuraDatabaseUser {
+	setResourceCondition(&set, ConditionStalled, status, reason, message, set.Generation)
+	return set
+}
+
+// GetStatusConditions returns a pointer to the Status.Conditions slice
+func (in *AuraDatabaseUser) GetStatusConditions() *[]metav1.Condition {
+	return &in.Status.Conditions
+}
+
+func (in *AuraDatabaseUser) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+func (in *AuraDatabaseUser) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
@@ -1,6 +1,7 @@
 package v1beta1
 
 import (
+	"github.com/fluxcd/pkg/apis/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -25,8 +26,88 @@ const (
 	CloudProviderAzure CloudProvider = "azure"
 )
 
+// DriftPolicy defines how the controller handles drift between the spec and the Aura instance
+// +kubebuilder:validation:Enum=Correct;Report
+type DriftPolicy string
+
+const (
+	// DriftPolicyCorrect reverts changes made outside of the controller
+	DriftPolicyCorrect DriftPolicy = "Correct"
+	// DriftPolicyReport only reports drift in the Drifted condition
+	DriftPolicyReport DriftPolicy = "Report"
+)
+
+// ChangePolicy defines how disruptive changes to an Aura instance are applied
+// +kubebuilder:validation:Enum=Automatic;RequireApproval
+type ChangePolicy string
+
+const (
+	// ChangePolicyAutomatic applies all changes immediately
+	ChangePolicyAutomatic ChangePolicy = "Automatic"
+	// ChangePolicyRequireApproval holds disruptive changes until they are approved
+	ChangePolicyRequireApproval ChangePolicy = "RequireApproval"
+)
+
+// Weekday is a day of the week
+// +kubebuilder:validation:Enum=Monday;Tuesday;Wednesday;Thursday;Friday;Saturday;Sunday
+type Weekday string
+
+// MaintenanceWindow is a recurring time window in which disruptive operations may be applied
+type MaintenanceWindow struct {
+	// Days of the week on which the window starts, defaults to every day
+	// +optional
+	Days []Weekday `json:"days,omitempty"`
+
+	// Start is the time of the day at which the window starts in the format HH:MM
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// Duration of the window
+	Duration metav1.Duration `json:"duration"`
+
+	// TimeZone is the IANA time zone of the start time, defaults to UTC
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// AuraOperation is a mutating Aura API operation
+type AuraOperation string
+
+const (
+	AuraOperationCreate  AuraOperation = "Create"
+	AuraOperationPatch   AuraOperation = "Patch"
+	AuraOperationUpgrade AuraOperation = "Upgrade"
+	AuraOperationResume  AuraOperation = "Resume"
+)
+
+const (
+	// DryRunAnnotation enables the dry run mode for a single AuraInstance if set to "true"
+	DryRunAnnotation = "neo4j.infra.doodle.com/dry-run"
+
+	// ApprovedChangesAnnotation approves the disruptive changes matching status.plannedChangesHash.
+	// It is removed once the approved changes have been applied.
+	ApprovedChangesAnnotation = "neo4j.infra.doodle.com/approved-changes"
+
+	// BootstrapAnnotation records the applied bootstrap scripts.
+	// Unlike the status it is kept if the AuraInstance is restored from a backup.
+	BootstrapAnnotation = "neo4j.infra.doodle.com/bootstrap"
+)
+
+const (
+	// InstanceNameLabel is set on replicated connection secrets and references the source AuraInstance
+	InstanceNameLabel = "neo4j.infra.doodle.com/instance-name"
+
+	// InstanceNamespaceLabel is set on replicated connection secrets and references the namespace of the source AuraInstance
+	InstanceNamespaceLabel = "neo4j.infra.doodle.com/instance-namespace"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.instanceStatus",description=""
+// +kubebuilder:printcolumn:name="Tier",type="string",JSONPath=".spec.tier",description=""
+// +kubebuilder:printcolumn:name="Memory",type="string",JSONPath=".status.memory",description=""
+// +kubebuilder:printcolumn:name="Region",type="string",JSONPath=".status.region",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
 type AuraInstance struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -67,7 +148,7 @@ type AuraInstanceSpec struct {
 
 	// ConnectionSecret is a reference to a secret which will contain the connection details.
 	// By default this will be ${metadataname}-connection
-	ConnectionSecret LocalObjectReference `json:"connectionSecret,omitempty"`
+	ConnectionSecret ConnectionSecretSpec `json:"connectionSecret,omitempty"`
 
 	// VectorOptimized specifies the vector optimization configuration of the instance
 	// +optional
@@ -85,18 +166,195 @@ type AuraInstanceSpec struct {
 	// +optional
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
 
-	// Interval at which the controller should reconcile the instance
+	// Interval at which the controller should reconcile the instance.
+	// Defaults to the controller wide default interval.
 	// +optional
 	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// DriftPolicy defines if drift detected on the Aura instance is corrected or only reported
+	// +kubebuilder:default=Correct
+	// +optional
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+
+	// ChangePolicy defines if disruptive changes (memory downsizing and tier upgrades)
+	// are applied automatically or require an approval.
+	// +kubebuilder:default=Automatic
+	// +optional
+	ChangePolicy ChangePolicy `json:"changePolicy,omitempty"`
+
+	// MaintenanceWindows in which patches and upgrades of the instance are applied.
+	// Changes are applied immediately if no window is configured.
+	// +optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// PasswordRotation rotates the password of the admin user periodically
+	// +optional
+	PasswordRotation *PasswordRotation `json:"passwordRotation,omitempty"`
+
+	// Bootstrap runs Cypher scripts once the instance is running
+	// +optional
+	Bootstrap *Bootstrap `json:"bootstrap,omitempty"`
+
+	// ReadinessCheck verifies the database is reachable over Bolt before the instance is considered ready
+	// +optional
+	ReadinessCheck *ReadinessCheck `json:"readinessCheck,omitempty"`
+
+	// Outputs publishes the non-sensitive connection details of the instance
+	// +optional
+	Outputs *Outputs `json:"outputs,omitempty"`
+}
+
+// Outputs defines the objects the non-sensitive connection details are published to
+type Outputs struct {
+	// ConfigMap containing the keys instanceID, name, connectionURL, scheme, host, port, region, cloudProvider, tier and version
+	// +optional
+	ConfigMap *OutputObject `json:"configMap,omitempty"`
+
+	// Service of type ExternalName pointing to the instance host
+	// +optional
+	Service *OutputObject `json:"service,omitempty"`
+
+	// ScrapeConfig of the Prometheus operator scraping the Aura metrics integration endpoint of the instance.
+	// The metrics are fetched using the Aura API credentials of the instance.
+	// +optional
+	ScrapeConfig *ScrapeConfigOutput `json:"scrapeConfig,omitempty"`
+}
+
+// ScrapeConfigOutput is a Prometheus operator ScrapeConfig created by the controller
+type ScrapeConfigOutput struct {
+	// Name of the ScrapeConfig, defaults to the instance name
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Labels added to the ScrapeConfig, used by Prometheus to select it
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Interval at which the metrics are scraped, defaults to the interval configured in Prometheus
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// OutputObject is an object created by the controller
+type OutputObject struct {
+	// Name of the object, defaults to the instance name
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// ReadinessCheck defines the Bolt connectivity check of an instance
+type ReadinessCheck struct {
+	// Enabled runs RETURN 1 using the instance credentials, the instance is only ready if the query succeeds
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Timeout of the check, defaults to 10s
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// Bootstrap defines Cypher scripts which are executed once on the instance
+type Bootstrap struct {
+	// ConfigMaps containing Cypher scripts. Every key is a script, scripts are executed
+	// in the order of the ConfigMaps and sorted by key within a ConfigMap.
+	ConfigMaps []LocalObjectReference `json:"configMaps"`
+
+	// Database the scripts are executed against, defaults to the default database
+	// +optional
+	Database string `json:"database,omitempty"`
+}
+
+// PasswordRotation defines the rotation of the admin user password
+type PasswordRotation struct {
+	// Interval at which the password is rotated
+	// +kubebuilder:validation:Required
+	Interval metav1.Duration `json:"interval"`
+}
+
+// ConnectionSecretSpec defines the secret which contains the connection details
+type ConnectionSecretSpec struct {
+	// Name of the secret, defaults to ${metadataname}-connection
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Template renders the secret contents.
+	// By default the secret contains the keys username, password, connectionURL, type, provider, host, port and uri.
+	// +optional
+	Template *ConnectionSecretTemplate `json:"template,omitempty"`
+
+	// ReplicateTo keeps copies of the connection secret in other namespaces.
+	// Only namespaces allowed by the controller are replicated to.
+	// +optional
+	ReplicateTo *SecretReplication `json:"replicateTo,omitempty"`
+
+	// Sink writes the connection secret to an external secret store in addition to the Kubernetes secret
+	// +optional
+	Sink *ConnectionSecretSink `json:"sink,omitempty"`
+}
+
+// ConnectionSecretSink defines an external secret store
+type ConnectionSecretSink struct {
+	// Vault writes the connection secret to a HashiCorp Vault KV version 2 secrets engine
+	// +optional
+	Vault *VaultSink `json:"vault,omitempty"`
+}
+
+// VaultSink defines the Vault secret the connection secret is written to
+type VaultSink struct {
+	// Address of the Vault server, e.g. https://vault.example.com:8200
+	// +kubebuilder:validation:Required
+	Address string `json:"address"`
+
+	// Namespace is the Vault Enterprise namespace
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Mount path of the KV version 2 secrets engine, defaults to secret
+	// +optional
+	Mount string `json:"mount,omitempty"`
+
+	// Path of the secret within the secrets engine
+	// +kubebuilder:validation:Required
+	Path string `json:"path"`
+
+	// TokenSecret references a secret in the same namespace containing the Vault token in the key token
+	// +kubebuilder:validation:Required
+	TokenSecret LocalObjectReference `json:"tokenSecret"`
+}
+
+// SecretReplication selects the namespaces the connection secret is replicated to
+type SecretReplication struct {
+	// Namespaces the connection secret is replicated to
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// NamespaceSelector selects the namespaces the connection secret is replicated to
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// ConnectionSecretTemplate defines how the contents of the connection secret are rendered
+type ConnectionSecretTemplate struct {
+	// Data maps secret keys to Go templates.
+	// Available fields are .InstanceID, .Name, .TenantID, .ConnectionURL, .Scheme, .Host, .Port,
+	// .Username, .Password, .Region, .CloudProvider, .Tier and .Version.
+	// +optional
+	Data map[string]string `json:"data,omitempty"`
 }
 
 type AuraInstanceStatus struct {
+	meta.ReconcileRequestStatus `json:",inline"`
+
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 
 	// ObservedGeneration is the last generation reconciled by the controller
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
+	// LastAppliedGeneration is the last generation whose spec has been applied to the Aura instance
+	// +optional
+	LastAppliedGeneration int64 `json:"lastAppliedGeneration,omitempty"`
+
 	// InstanceID is the Aura instance ID
 	// +optional
 	InstanceID string `json:"instanceId,omitempty"`
@@ -105,9 +363,119 @@ type AuraInstanceStatus struct {
 	// +optional
 	ConnectionSecret string `json:"connectionUri,omitempty"`
 
+	// Binding exposes the connection secret as Service Binding for Kubernetes provisioned service
+	// +optional
+	Binding *LocalObjectReference `json:"binding,omitempty"`
+
+	// ReplicatedTo lists the namespaces the connection secret is replicated to
+	// +optional
+	ReplicatedTo []string `json:"replicatedTo,omitempty"`
+
 	// Status represents the current status of the Aura instance
 	// +optional
 	InstanceStatus string `json:"instanceStatus,omitempty"`
+
+	// ConnectionURL is the URL for connecting to the instance
+	// +optional
+	ConnectionURL string `json:"connectionURL,omitempty"`
+
+	// Type is the instance type reported by Aura
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Memory is the memory size of the instance reported by Aura
+	// +optional
+	Memory string `json:"memory,omitempty"`
+
+	// Storage is the storage size of the instance reported by Aura
+	// +optional
+	Storage string `json:"storage,omitempty"`
+
+	// Region is the region the instance is hosted in
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// CreatedAt is the time the instance was created in Aura
+	// +optional
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+
+	// GraphNodes is the number of nodes in the instance, only reported for free instances
+	// +optional
+	GraphNodes *int64 `json:"graphNodes,omitempty"`
+
+	// GraphRelationships is the number of relationships in the instance, only reported for free instances
+	// +optional
+	GraphRelationships *int64 `json:"graphRelationships,omitempty"`
+
+	// SecondariesCount is the number of secondaries of the instance, only reported for virtual dedicated cloud instances
+	// +optional
+	SecondariesCount *int32 `json:"secondariesCount,omitempty"`
+
+	// PlannedChanges lists the Aura API operations which are planned but not yet applied
+	// +optional
+	PlannedChanges []PlannedChange `json:"plannedChanges,omitempty"`
+
+	// PlannedChangesHash is the hash of the planned disruptive changes.
+	// It needs to be set as approved-changes annotation to approve them.
+	// +optional
+	PlannedChangesHash string `json:"plannedChangesHash,omitempty"`
+
+	// LastPasswordRotation is the time the admin user password was last rotated
+	// +optional
+	LastPasswordRotation *metav1.Time `json:"lastPasswordRotation,omitempty"`
+
+	// ConfigMap is the name of the ConfigMap which contains the non-sensitive connection details
+	// +optional
+	ConfigMap string `json:"configMap,omitempty"`
+
+	// Service is the name of the ExternalName Service pointing to the instance
+	// +optional
+	Service string `json:"service,omitempty"`
+
+	// ScrapeConfig is the name of the ScrapeConfig scraping the Aura metrics of the instance
+	// +optional
+	ScrapeConfig string `json:"scrapeConfig,omitempty"`
+
+	// MetricsIntegrationURL is the endpoint exposing the Aura metrics of the instance
+	// +optional
+	MetricsIntegrationURL string `json:"metricsIntegrationURL,omitempty"`
+
+	// Bootstrap lists the bootstrap scripts which have been applied
+	// +optional
+	Bootstrap []AppliedScript `json:"bootstrap,omitempty"`
+}
+
+// AppliedScript is a Cypher script which has been executed on the instance
+type AppliedScript struct {
+	// Name of the script
+	Name string `json:"name"`
+
+	// Checksum is the sha256 checksum of the script at the time it was applied
+	Checksum string `json:"checksum"`
+
+	// AppliedAt is the time the script was applied
+	AppliedAt metav1.Time `json:"appliedAt"`
+}
+
+// PlannedChange describes a mutating Aura API call planned by the controller
+type PlannedChange struct {
+	// Operation is the type of the Aura API call
+	Operation AuraOperation `json:"operation"`
+
+	// Request is the Aura API request, e.g. PATCH /instances/{instanceId}
+	Request string `json:"request"`
+
+	// Changes lists the changed fields
+	// +optional
+	Changes []string `json:"changes,omitempty"`
+
+	// Disruptive is true if the change causes unavailability or can't be reverted
+	// +optional
+	Disruptive bool `json:"disruptive,omitempty"`
+
+	// ScheduledAt is the start of the maintenance window the change is deferred to
+	// +optional
+	ScheduledAt *metav1.Time `json:"scheduledAt,omitempty"`
 }
 
 // AuraInstanceList contains a list of AuraInstance.
@@ -127,6 +495,41 @@ func AuraInstanceReconciling(set AuraInstance, status metav1.ConditionStatus, re
 	return set
 }
 
+func AuraInstanceStalled(set AuraInstance, status metav1.ConditionStatus, reason, message string) AuraInstance {
+	setResourceCondition(&set, ConditionStalled, status, reason, message, set.Generation)
+	return set
+}
+
+func AuraInstanceDrifted(set AuraInstance, status metav1.ConditionStatus, reason, message string) AuraInstance {
+	setResourceCondition(&set, ConditionDrifted, status, reason, message, set.Generation)
+	return set
+}
+
+func AuraInstancePendingApproval(set AuraInstance, status metav1.ConditionStatus, reason, message string) AuraInstance {
+	setResourceCondition(&set, ConditionPendingApproval, status, reason, message, set.Generation)
+	return set
+}
+
+func AuraInstancePasswordRotated(set AuraInstance, status metav1.ConditionStatus, reason, message string) AuraInstance {
+	setResourceCondition(&set, ConditionPasswordRotated, status, reason, message, set.Generation)
+	return set
+}
+
+func AuraInstanceBootstrapped(set AuraInstance, status metav1.ConditionStatus, reason, message string) AuraInstance {
+	setResourceCondition(&set, ConditionBootstrapped, status, reason, message, set.Generation)
+	return set
+}
+
+func AuraInstanceDatabaseReachable(set AuraInstance, status metav1.ConditionStatus, reason, message string) AuraInstance {
+	setResourceCondition(&set, ConditionDatabaseReachable, status, reason, message, set.Generation)
+	return set
+}
+
+func AuraInstanceScrapeConfigReady(set AuraInstance, status metav1.ConditionStatus, reason, message string) AuraInstance {
+	setResourceCondition(&set, ConditionScrapeConfigReady, status, reason, message, set.Generation)
+	return set
+}
+
 func AuraInstanceReady(set AuraInstance, status metav1.ConditionStatus, reason, message string) AuraInstance {
 	setResourceCondition(&set, ConditionReady, status, reason, message, set.Generation)
 	return set
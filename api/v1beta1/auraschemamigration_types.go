@@ -0,0 +1,102 @@
+package v1beta1
+
+import (
+	"github.com/fluxcd/pkg/apis/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AuraSchemaMigration is the Schema for the auraschemamigrations API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+type AuraSchemaMigration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AuraSchemaMigrationSpec   `json:"spec,omitempty"`
+	Status AuraSchemaMigrationStatus `json:"status,omitempty"`
+}
+
+type AuraSchemaMigrationSpec struct {
+	// InstanceRef references the AuraInstance in the same namespace the migrations are applied to
+	// +kubebuilder:validation:Required
+	InstanceRef LocalObjectReference `json:"instanceRef"`
+
+	// ConfigMaps containing the migrations. Every key is a migration named V<version>__<description>.cypher,
+	// e.g. V1__create_constraints.cypher or V1.1__backfill_users.cypher. Migrations are applied ordered by version.
+	// +kubebuilder:validation:Required
+	ConfigMaps []LocalObjectReference `json:"configMaps"`
+
+	// Database the migrations are applied to, defaults to the default database
+	// +optional
+	Database string `json:"database,omitempty"`
+
+	// Suspend tells the controller to suspend reconciliation for this migration
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+type AuraSchemaMigrationStatus struct {
+	meta.ReconcileRequestStatus `json:",inline"`
+
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the last generation reconciled by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// CurrentVersion is the version of the last applied migration
+	// +optional
+	CurrentVersion string `json:"currentVersion,omitempty"`
+
+	// FailedMigration is the migration which failed to apply.
+	// Further migrations are blocked until the failed migration is changed.
+	// +optional
+	FailedMigration *FailedMigration `json:"failedMigration,omitempty"`
+}
+
+// FailedMigration describes a migration which failed to apply
+type FailedMigration struct {
+	// Version of the migration
+	Version string `json:"version"`
+
+	// Checksum of the migration which failed
+	Checksum string `json:"checksum"`
+
+	// Message is the error returned by the instance
+	Message string `json:"message"`
+}
+
+// AuraSchemaMigrationList contains a list of AuraSchemaMigration.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type AuraSchemaMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AuraSchemaMigration `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AuraSchemaMigration{}, &AuraSchemaMigrationList{})
+}
+
+func AuraSchemaMigrationFailed(set AuraSchemaMigration, status metav1.ConditionStatus, reason, message string) AuraSchemaMigration {
+	setResourceCondition(&set, ConditionMigrationFailed, status, reason, message, set.Generation)
+	return set
+}
+
+func AuraSchemaMigrationReady(set AuraSchemaMigration, status metav1.ConditionStatus, reason, message string) AuraSchemaMigration {
+	setResourceCondition(&set, ConditionReady, status, reason, message, set.Generation)
+	return set
+}
+
+// GetStatusConditions returns a pointer to the Status.Conditions slice
+func (in *AuraSchemaMigration) GetStatusConditions() *[]metav1.Condition {
+	return &in.Status.Conditions
+}
+
+func (in *AuraSchemaMigration) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+func (in *AuraSchemaMigration) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
@@ -16,10 +16,21 @@ type SecretReference struct {
 	ClientSecretKey string `json:"clientSecretKey,omitempty"`
 }
 
+// Finalizer is used to clean up resources in Neo4j before the object is deleted
+const Finalizer = "finalizers.neo4j.infra.doodle.com"
+
 const (
-	ConditionReady        = "Ready"
-	ConditionReconciling  = "Reconciling"
-	ConditionScaledToZero = "ScaledToZero"
+	ConditionReady             = "Ready"
+	ConditionReconciling       = "Reconciling"
+	ConditionStalled           = "Stalled"
+	ConditionScaledToZero      = "ScaledToZero"
+	ConditionDrifted           = "Drifted"
+	ConditionPendingApproval   = "PendingApproval"
+	ConditionPasswordRotated   = "PasswordRotated"
+	ConditionBootstrapped      = "Bootstrapped"
+	ConditionMigrationFailed   = "MigrationFailed"
+	ConditionDatabaseReachable = "DatabaseReachable"
+	ConditionScrapeConfigReady = "ScrapeConfigReady"
 )
 
 // ConditionalResource is a resource with conditions
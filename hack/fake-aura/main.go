@@ -0,0 +1,64 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// fake-aura runs the fake Aura API for local development of the controller
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/fake"
+	flag "github.com/spf13/pflag"
+)
+
+func main() {
+	var (
+		addr            string
+		projects        []string
+		transitionDelay time.Duration
+		clientID        string
+		clientSecret    string
+	)
+
+	flag.StringVar(&addr, "addr", ":8080", "The address the fake Aura API binds to.")
+	flag.StringSliceVar(&projects, "project", []string{"default"}, "The ids of the projects instances can be created in.")
+	flag.DurationVar(&transitionDelay, "transition-delay", 30*time.Second, "The time it takes an instance to reach its next state, e.g. from creating to running.")
+	flag.StringVar(&clientID, "client-id", "", "The client id accepted by the token endpoint. Any credentials are accepted if not set.")
+	flag.StringVar(&clientSecret, "client-secret", "", "The client secret accepted by the token endpoint.")
+	flag.Parse()
+
+	opts := []fake.Option{fake.WithTransitionDelay(transitionDelay)}
+	if clientID != "" {
+		opts = append(opts, fake.WithCredentials(clientID, clientSecret))
+	}
+
+	server := fake.New(opts...)
+	for _, project := range projects {
+		server.AddProject(project, project)
+	}
+
+	log.Printf("fake Aura API listening on %s, use --base-url=http://localhost%s%s --token-url=http://localhost%s%s", addr, addr, fake.APIPrefix, addr, fake.TokenPath)
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           server,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Fatal(srv.ListenAndServe())
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bolt connects to Neo4j instances using the Bolt protocol
+package bolt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// SystemDatabase is used for administrative commands
+const SystemDatabase = "system"
+
+// Auth holds the credentials used to connect to an instance
+type Auth struct {
+	Username string
+	Password string
+}
+
+// Conn is an authenticated connection to an instance
+type Conn interface {
+	// Run executes a query in an auto-commit transaction and returns all records.
+	// The default database is used if database is empty.
+	Run(ctx context.Context, database, query string, params map[string]any) ([]map[string]any, error)
+	Close(ctx context.Context) error
+}
+
+// Dialer opens connections to instances
+type Dialer interface {
+	// Dial connects to the instance and verifies the credentials
+	Dial(ctx context.Context, url string, auth Auth) (Conn, error)
+}
+
+// IsUnauthorized returns true if the error is caused by invalid credentials
+func IsUnauthorized(err error) bool {
+	var authErr *neo4j.InvalidAuthenticationError
+	return errors.As(err, &authErr)
+}
+
+// QuoteIdentifier escapes a name for use as identifier in Cypher, e.g. as user or role name
+func QuoteIdentifier(name string) string {
+	return fmt.Sprintf("`%s`", strings.ReplaceAll(name, "`", "``"))
+}
+
+// NewDialer returns a Dialer using the neo4j driver
+func NewDialer() Dialer {
+	return &driverDialer{}
+}
+
+type driverDialer struct{}
+
+func (d *driverDialer) Dial(ctx context.Context, url string, auth Auth) (Conn, error) {
+	driver, err := neo4j.NewDriverWithContext(url, neo4j.BasicAuth(auth.Username, auth.Password, ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create driver: %w", err)
+	}
+
+	if err := driver.VerifyAuthentication(ctx, nil); err != nil {
+		_ = driver.Close(ctx)
+		return nil, err
+	}
+
+	return &driverConn{driver: driver}, nil
+}
+
+type driverConn struct {
+	driver neo4j.DriverWithContext
+}
+
+func (c *driverConn) Run(ctx context.Context, database, query string, params map[string]any) ([]map[string]any, error) {
+	session := c.driver.NewSession(ctx, neo4j.SessionConfig{DatabaseName: database})
+	defer func() {
+		_ = session.Close(ctx)
+	}()
+
+	result, err := session.Run(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := result.Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]map[string]any, 0, len(records))
+	for _, record := range records {
+		rows = append(rows, record.AsMap())
+	}
+
+	return rows, nil
+}
+
+func (c *driverConn) Close(ctx context.Context) error {
+	return c.driver.Close(ctx)
+}
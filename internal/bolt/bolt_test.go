@@ -0,0 +1,43 @@
+package bolt
+
+import (
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	assert.Equal(t, "`app`", QuoteIdentifier("app"))
+	assert.Equal(t, "`my-app`", QuoteIdentifier("my-app"))
+	assert.Equal(t, "`a``b`", QuoteIdentifier("a`b"))
+}
+
+func TestSplitStatements(t *testing.T) {
+	statements := SplitStatements(`
+// constraints
+CREATE CONSTRAINT user_id IF NOT EXISTS FOR (u:User) REQUIRE u.id IS UNIQUE;
+/* seed data; with a semicolon */
+MERGE (c:Config {key: 'separator', value: ';'});
+MERGE (:Tag {name: "a;b"})
+;
+CREATE INDEX ` + "`idx;name`" + ` IF NOT EXISTS FOR (t:Tag) ON (t.name);
+MERGE (:Quote {value: 'it\'s; fine'})`)
+
+	assert.Equal(t, []string{
+		"CREATE CONSTRAINT user_id IF NOT EXISTS FOR (u:User) REQUIRE u.id IS UNIQUE",
+		"MERGE (c:Config {key: 'separator', value: ';'})",
+		`MERGE (:Tag {name: "a;b"})`,
+		"CREATE INDEX `idx;name` IF NOT EXISTS FOR (t:Tag) ON (t.name)",
+		`MERGE (:Quote {value: 'it\'s; fine'})`,
+	}, statements)
+}
+
+func TestSplitStatementsEmpty(t *testing.T) {
+	assert.Empty(t, SplitStatements("  \n// only a comment\n;;"))
+}
+
+func TestChecksum(t *testing.T) {
+	assert.Equal(t, Checksum("RETURN 1"), Checksum("RETURN 1"))
+	assert.NotEqual(t, Checksum("RETURN 1"), Checksum("RETURN 2"))
+	assert.Len(t, Checksum("RETURN 1"), 64)
+}
@@ -0,0 +1,544 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	infrav1beta1 "github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/bolt"
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/tracing"
+	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	"github.com/fluxcd/pkg/runtime/predicates"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+//+kubebuilder:rbac:groups=neo4j.infra.doodle.com,resources=auradatabaseusers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=neo4j.infra.doodle.com,resources=auradatabaseusers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=neo4j.infra.doodle.com,resources=auradatabaseusers/finalizers,verbs=update
+
+// AuraDatabaseUserReconciler reconciles an AuraDatabaseUser object
+type AuraDatabaseUserReconciler struct {
+	client.Client
+	Log        logr.Logger
+	Recorder   record.EventRecorder
+	BoltDialer bolt.Dialer
+}
+
+type AuraDatabaseUserReconcilerOptions struct {
+	MaxConcurrentReconciles int
+}
+
+const instanceRefIndexKey = ".spec.instanceRef"
+
+func (r *AuraDatabaseUserReconciler) SetupWithManager(mgr ctrl.Manager, opts AuraDatabaseUserReconcilerOptions) error {
+	if r.BoltDialer == nil {
+		r.BoltDialer = bolt.NewDialer()
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &infrav1beta1.AuraDatabaseUser{}, instanceRefIndexKey,
+		func(o client.Object) []string {
+			user := o.(*infrav1beta1.AuraDatabaseUser)
+			return []string{
+				fmt.Sprintf("%s/%s", user.GetNamespace(), user.Spec.InstanceRef.Name),
+			}
+		},
+	); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&infrav1beta1.AuraDatabaseUser{}, builder.WithPredicates(
+			predicate.Or(predicate.GenerationChangedPredicate{}, predicates.ReconcileRequestedPredicate{}),
+		)).
+		Owns(&corev1.Secret{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: opts.MaxConcurrentReconciles}).
+		Watches(
+			&infrav1beta1.AuraInstance{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForInstanceChange),
+		).
+		Complete(r)
+}
+
+func (r *AuraDatabaseUserReconciler) requestsForInstanceChange(ctx context.Context, o client.Object) []reconcile.Request {
+	instance, ok := o.(*infrav1beta1.AuraInstance)
+	if !ok {
+		panic(fmt.Sprintf("expected an AuraInstance, got %T", o))
+	}
+
+	var list infrav1beta1.AuraDatabaseUserList
+	if err := r.List(ctx, &list, client.MatchingFields{
+		instanceRefIndexKey: objectKey(instance).String(),
+	}); err != nil {
+		return nil
+	}
+
+	var reqs []reconcile.Request
+	for _, user := range list.Items {
+		r.Log.V(1).Info("referenced AuraInstance from an AuraDatabaseUser changed detected", "namespace", user.GetNamespace(), "name", user.GetName())
+		reqs = append(reqs, reconcile.Request{NamespacedName: objectKey(&user)})
+	}
+
+	return reqs
+}
+
+func (r *AuraDatabaseUserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Log.WithValues("namespace", req.Namespace, "name", req.Name)
+
+	ctx = tracing.ContextWithAttributes(ctx,
+		tracing.AttributeNamespace.String(req.Namespace),
+		tracing.AttributeName.String(req.Name),
+	)
+
+	ctx, span := tracing.Start(ctx, "AuraDatabaseUser.Reconcile")
+	defer span.End()
+
+	user := infrav1beta1.AuraDatabaseUser{}
+	err := r.Get(ctx, req.NamespacedName, &user)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		tracing.RecordError(span, err)
+		return reconcile.Result{}, err
+	}
+
+	if !user.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, user, logger)
+	}
+
+	if user.Spec.Suspend {
+		logger.Info("aura database user is suspended")
+		return ctrl.Result{}, nil
+	}
+
+	if !controllerutil.ContainsFinalizer(&user, infrav1beta1.Finalizer) {
+		controllerutil.AddFinalizer(&user, infrav1beta1.Finalizer)
+		if err := r.Update(ctx, &user); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	logger.Info("reconciling aura database user")
+	user, result, err := r.reconcile(ctx, user, logger)
+	if requestedAt, ok := meta.ReconcileAnnotationValue(user.GetAnnotations()); ok {
+		user.Status.SetLastHandledReconcileRequest(requestedAt)
+	}
+
+	user.Status.ObservedGeneration = user.GetGeneration()
+
+	if err != nil {
+		logger.Error(err, "reconcile error occurred")
+		user = infrav1beta1.AuraDatabaseUserReady(user, metav1.ConditionFalse, "ReconciliationFailed", err.Error())
+		r.Recorder.Event(&user, "Warning", "ReconciliationFailed", err.Error())
+		tracing.RecordError(span, err)
+	}
+
+	// Update status after reconciliation
+	if err := r.patchStatus(ctx, &user); err != nil {
+		logger.Error(err, "unable to update status after reconciliation")
+		return ctrl.Result{Requeue: true}, err
+	}
+
+	return result, err
+}
+
+// databaseUsername returns the name of the database user
+func databaseUsername(user infrav1beta1.AuraDatabaseUser) string {
+	if user.Spec.Username != "" {
+		return user.Spec.Username
+	}
+
+	return user.Name
+}
+
+// databaseUserSecretName returns the name of the secret which contains the user credentials
+func databaseUserSecretName(user infrav1beta1.AuraDatabaseUser) string {
+	if user.Spec.Secret.Name != "" {
+		return user.Spec.Secret.Name
+	}
+
+	return fmt.Sprintf("%s-credentials", user.Name)
+}
+
+// builtinRoles are the roles every Neo4j instance comes with
+var builtinRoles = []string{"PUBLIC", "reader", "editor", "publisher", "architect", "admin"}
+
+// validateUsername rejects users which would change the admin user or could be mistaken for a built-in role
+func validateUsername(username, adminUsername string) error {
+	if strings.EqualFold(username, adminUsername) {
+		return fmt.Errorf("username %q is the admin user of the instance", username)
+	}
+
+	for _, role := range builtinRoles {
+		if strings.EqualFold(username, role) {
+			return fmt.Errorf("username %q is the name of a built-in role", username)
+		}
+	}
+
+	return nil
+}
+
+// privilegePattern matches the graph and database privileges which can be granted to the role of a user.
+// DBMS privileges are not allowed as they would allow the user to manage other users and roles.
+var privilegePattern = func() *regexp.Regexp {
+	name := "(?:[A-Za-z_][A-Za-z0-9_.]*|`[^`]+`)"
+	names := fmt.Sprintf(`(?:\*|%s(?:\s*,\s*%s)*)`, name, name)
+	properties := fmt.Sprintf(`\{\s*%s\s*\}`, names)
+
+	graphAction := fmt.Sprintf(`(?:TRAVERSE|(?:READ|MATCH|MERGE|SET\s+PROPERTY)\s*%s|CREATE|DELETE|WRITE|(?:SET|REMOVE)\s+LABELS?\s+%s|ALL(?:\s+GRAPH)?\s+PRIVILEGES)`, properties, names)
+	graph := fmt.Sprintf(`(?:HOME\s+GRAPH|GRAPHS?\s+%s)(?:\s+(?:ELEMENTS?|NODES?|RELATIONSHIPS?)\s+%s)?`, names, names)
+
+	databaseAction := `(?:ACCESS|START|STOP|(?:CREATE|DROP|SHOW)\s+(?:INDEX|INDEXES|CONSTRAINT|CONSTRAINTS)|(?:INDEX|CONSTRAINT|NAME|TRANSACTION)(?:\s+MANAGEMENT)?|CREATE\s+NEW\s+(?:(?:NODE\s+)?LABELS?|(?:RELATIONSHIP\s+)?TYPES?|(?:PROPERTY\s+)?NAMES?)|ALL(?:\s+DATABASE)?\s+PRIVILEGES)`
+	database := fmt.Sprintf(`(?:HOME\s+DATABASE|DATABASES?\s+%s)`, names)
+
+	return regexp.MustCompile(fmt.Sprintf(`(?i)^\s*(?:%s\s+ON\s+%s|%s\s+ON\s+%s)\s*$`, graphAction, graph, databaseAction, database))
+}()
+
+// validatePrivileges validates the privileges before they are used in statements
+func validatePrivileges(privileges []string) error {
+	for _, privilege := range privileges {
+		if !privilegePattern.MatchString(privilege) {
+			return fmt.Errorf("invalid privilege %q, only graph and database privileges in the form <privilege> ON <graph|database> are allowed", privilege)
+		}
+	}
+
+	return nil
+}
+
+// databaseUserRole returns the name of the role holding the privileges of the user.
+// The role is suffixed to not collide with built-in roles or roles managed outside of the controller.
+func databaseUserRole(user infrav1beta1.AuraDatabaseUser) string {
+	return fmt.Sprintf("%s_privileges", databaseUsername(user))
+}
+
+// desiredRoles returns the roles granted to the user including the role holding the privileges
+func desiredRoles(user infrav1beta1.AuraDatabaseUser) []string {
+	roles := slices.Clone(user.Spec.Roles)
+	if len(user.Spec.Privileges) > 0 && !slices.Contains(roles, databaseUserRole(user)) {
+		roles = append(roles, databaseUserRole(user))
+	}
+
+	return roles
+}
+
+// planUserStatements returns the statements which create or update the user, its roles and privileges
+func planUserStatements(user infrav1beta1.AuraDatabaseUser, exists, setPassword bool, password string) []statement {
+	username := bolt.QuoteIdentifier(databaseUsername(user))
+	role := bolt.QuoteIdentifier(databaseUserRole(user))
+	var statements []statement
+
+	// A renamed user starts without roles and privileges, the previous user and its role are dropped
+	var previous []statement
+	if user.Status.Username != "" && user.Status.Username != databaseUsername(user) {
+		renamed := user
+		renamed.Spec.Username = user.Status.Username
+		previous = planDropUserStatements(renamed)
+
+		user.Status.Roles = nil
+		user.Status.Privileges = nil
+	}
+
+	switch {
+	case !exists:
+		statements = append(statements, statement{
+			query:  fmt.Sprintf("CREATE USER %s SET PLAINTEXT PASSWORD $password CHANGE NOT REQUIRED", username),
+			params: map[string]any{"password": password},
+		})
+	case setPassword:
+		statements = append(statements, statement{
+			query:  fmt.Sprintf("ALTER USER %s SET PLAINTEXT PASSWORD $password CHANGE NOT REQUIRED", username),
+			params: map[string]any{"password": password},
+		})
+	}
+
+	// The privileges are granted to a role dedicated to the user
+	if len(user.Spec.Privileges) > 0 {
+		statements = append(statements, statement{
+			query: fmt.Sprintf("CREATE ROLE %s IF NOT EXISTS", role),
+		})
+
+		for _, privilege := range user.Spec.Privileges {
+			statements = append(statements, statement{
+				query: fmt.Sprintf("GRANT %s TO %s", privilege, role),
+			})
+		}
+	}
+
+	for _, privilege := range user.Status.Privileges {
+		if len(user.Spec.Privileges) > 0 && !slices.Contains(user.Spec.Privileges, privilege) {
+			statements = append(statements, statement{
+				query: fmt.Sprintf("REVOKE %s FROM %s", privilege, role),
+			})
+		}
+	}
+
+	roles := desiredRoles(user)
+	for _, role := range roles {
+		statements = append(statements, statement{
+			query: fmt.Sprintf("GRANT ROLE %s TO %s", bolt.QuoteIdentifier(role), username),
+		})
+	}
+
+	for _, role := range user.Status.Roles {
+		if !slices.Contains(roles, role) {
+			statements = append(statements, statement{
+				query: fmt.Sprintf("REVOKE ROLE %s FROM %s", bolt.QuoteIdentifier(role), username),
+			})
+		}
+	}
+
+	if len(user.Spec.Privileges) == 0 && len(user.Status.Privileges) > 0 {
+		statements = append(statements, statement{
+			query: fmt.Sprintf("DROP ROLE %s IF EXISTS", role),
+		})
+	}
+
+	return append(statements, previous...)
+}
+
+// planDropUserStatements returns the statements which remove the user and its role
+func planDropUserStatements(user infrav1beta1.AuraDatabaseUser) []statement {
+	statements := []statement{
+		{query: fmt.Sprintf("DROP USER %s IF EXISTS", bolt.QuoteIdentifier(databaseUsername(user)))},
+	}
+
+	if len(user.Status.Privileges) > 0 {
+		statements = append(statements, statement{
+			query: fmt.Sprintf("DROP ROLE %s IF EXISTS", bolt.QuoteIdentifier(databaseUserRole(user))),
+		})
+	}
+
+	return statements
+}
+
+// stallDatabaseUser marks the user as failed until its spec is changed
+func stallDatabaseUser(user infrav1beta1.AuraDatabaseUser, reason, message string) infrav1beta1.AuraDatabaseUser {
+	user = infrav1beta1.AuraDatabaseUserStalled(user, metav1.ConditionTrue, reason, message)
+	return infrav1beta1.AuraDatabaseUserReady(user, metav1.ConditionFalse, reason, message)
+}
+
+func (r *AuraDatabaseUserReconciler) reconcile(ctx context.Context, user infrav1beta1.AuraDatabaseUser, logger logr.Logger) (infrav1beta1.AuraDatabaseUser, ctrl.Result, error) {
+	conditions.Delete(&user, infrav1beta1.ConditionStalled)
+
+	var instance infrav1beta1.AuraInstance
+	if err := r.Get(ctx, types.NamespacedName{
+		Name:      user.Spec.InstanceRef.Name,
+		Namespace: user.Namespace,
+	}, &instance); err != nil {
+		return user, reconcile.Result{}, fmt.Errorf("failed to get instance: %w", err)
+	}
+
+	if instance.Status.InstanceStatus != string(auraclient.InstanceDataStatusRunning) {
+		user = infrav1beta1.AuraDatabaseUserReady(user, metav1.ConditionFalse, "InstanceNotReady", fmt.Sprintf("Instance %s is not running", instance.Name))
+		return user, reconcile.Result{RequeueAfter: time.Second * 30}, nil
+	}
+
+	if err := validatePrivileges(user.Spec.Privileges); err != nil {
+		return stallDatabaseUser(user, "InvalidPrivileges", err.Error()), reconcile.Result{}, nil
+	}
+
+	url, auth, err := instanceCredentials(ctx, r.Client, instance)
+	if errors.Is(err, errCredentialsUnavailable) {
+		user = infrav1beta1.AuraDatabaseUserReady(user, metav1.ConditionFalse, "CredentialsUnavailable", err.Error())
+		return user, reconcile.Result{}, nil
+	}
+
+	if err != nil {
+		return user, reconcile.Result{}, err
+	}
+
+	if err := validateUsername(databaseUsername(user), auth.Username); err != nil {
+		return stallDatabaseUser(user, "InvalidUsername", err.Error()), reconcile.Result{}, nil
+	}
+
+	conn, err := r.BoltDialer.Dial(ctx, url, auth)
+	if err != nil {
+		return user, reconcile.Result{}, fmt.Errorf("failed to connect to instance: %w", err)
+	}
+
+	defer func() {
+		_ = conn.Close(ctx)
+	}()
+
+	username := databaseUsername(user)
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      databaseUserSecretName(user),
+			Namespace: user.Namespace,
+		},
+	}
+
+	err = r.Get(ctx, client.ObjectKeyFromObject(&secret), &secret)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return user, reconcile.Result{}, fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	password := string(secret.Data[credentialsPasswordKey])
+	setPassword := password == "" || string(secret.Data[credentialsUsernameKey]) != username
+	if setPassword {
+		password, err = generatePassword()
+		if err != nil {
+			return user, reconcile.Result{}, err
+		}
+	}
+
+	// The secret is written first, the password would be lost if the secret can't be written after the user was created
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, &secret, func() error {
+		secret.Data = secretData(map[string]string{
+			credentialsUsernameKey:      username,
+			credentialsPasswordKey:      password,
+			credentialsConnectionURLKey: url,
+		})
+
+		return controllerutil.SetControllerReference(&user, &secret, r.Scheme())
+	}); err != nil {
+		return user, reconcile.Result{}, fmt.Errorf("failed to write secret: %w", err)
+	}
+
+	rows, err := conn.Run(ctx, bolt.SystemDatabase, "SHOW USERS YIELD user WHERE user = $user RETURN user", map[string]any{
+		"user": username,
+	})
+	if err != nil {
+		return user, reconcile.Result{}, fmt.Errorf("failed to lookup user: %w", err)
+	}
+
+	exists := len(rows) > 0
+
+	// The password is reset if it was changed outside of the controller
+	if exists && !setPassword {
+		valid, err := verifyPassword(ctx, r.BoltDialer, url, username, password)
+		if err != nil {
+			return user, reconcile.Result{}, fmt.Errorf("failed to verify user password: %w", err)
+		}
+
+		setPassword = !valid
+	}
+
+	if err := runStatements(ctx, conn, bolt.SystemDatabase, planUserStatements(user, exists, setPassword, password)); err != nil {
+		return user, reconcile.Result{}, err
+	}
+
+	if !exists {
+		r.Recorder.Event(&user, "Normal", "UserCreated", fmt.Sprintf("Created database user %q", username))
+	}
+
+	user.Status.Username = username
+	user.Status.Secret = secret.Name
+	user.Status.Roles = desiredRoles(user)
+	user.Status.Privileges = slices.Clone(user.Spec.Privileges)
+	user = infrav1beta1.AuraDatabaseUserReady(user, metav1.ConditionTrue, "UserReady", fmt.Sprintf("Database user %s is ready", username))
+
+	return user, reconcile.Result{}, nil
+}
+
+func (r *AuraDatabaseUserReconciler) reconcileDelete(ctx context.Context, user infrav1beta1.AuraDatabaseUser, logger logr.Logger) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(&user, infrav1beta1.Finalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.dropUser(ctx, user, logger); err != nil {
+		logger.Error(err, "failed to drop user")
+		r.Recorder.Event(&user, "Warning", "DeletionFailed", err.Error())
+		return ctrl.Result{}, err
+	}
+
+	controllerutil.RemoveFinalizer(&user, infrav1beta1.Finalizer)
+	return ctrl.Result{}, r.Update(ctx, &user)
+}
+
+// dropUser removes the user from the instance.
+// Users of instances which don't exist anymore or can't be accessed are skipped.
+func (r *AuraDatabaseUserReconciler) dropUser(ctx context.Context, user infrav1beta1.AuraDatabaseUser, logger logr.Logger) error {
+	var instance infrav1beta1.AuraInstance
+	err := r.Get(ctx, types.NamespacedName{
+		Name:      user.Spec.InstanceRef.Name,
+		Namespace: user.Namespace,
+	}, &instance)
+
+	if kerrors.IsNotFound(err) || (err == nil && !instance.DeletionTimestamp.IsZero()) {
+		logger.Info("instance is gone, skipping user removal")
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to get instance: %w", err)
+	}
+
+	url, auth, err := instanceCredentials(ctx, r.Client, instance)
+	if errors.Is(err, errCredentialsUnavailable) {
+		r.Recorder.Event(&user, "Warning", "UserNotDropped", fmt.Sprintf("Database user %q is not removed: %s", databaseUsername(user), err))
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// Users which were never managed by the controller, e.g. the admin user, are left untouched
+	if err := validateUsername(databaseUsername(user), auth.Username); err != nil {
+		r.Recorder.Event(&user, "Warning", "UserNotDropped", fmt.Sprintf("Database user %q is not removed: %s", databaseUsername(user), err))
+		return nil
+	}
+
+	conn, err := r.BoltDialer.Dial(ctx, url, auth)
+	if err != nil {
+		return fmt.Errorf("failed to connect to instance: %w", err)
+	}
+
+	defer func() {
+		_ = conn.Close(ctx)
+	}()
+
+	if err := runStatements(ctx, conn, bolt.SystemDatabase, planDropUserStatements(user)); err != nil {
+		return err
+	}
+
+	logger.Info("dropped database user", "user", databaseUsername(user))
+	return nil
+}
+
+func (r *AuraDatabaseUserReconciler) patchStatus(ctx context.Context, user *infrav1beta1.AuraDatabaseUser) error {
+	key := client.ObjectKeyFromObject(user)
+	latest := &infrav1beta1.AuraDatabaseUser{}
+	if err := r.Get(ctx, key, latest); err != nil {
+		return err
+	}
+
+	return r.Status().Patch(ctx, user, client.MergeFrom(latest))
+}
@@ -0,0 +1,284 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+var _ = Describe("AuraDatabaseUser controller", func() {
+	const (
+		timeout  = time.Second * 4
+		interval = time.Millisecond * 600
+	)
+
+	userName := fmt.Sprintf("user-%s", rand.String(5))
+	instanceName := fmt.Sprintf("instance-%s", rand.String(5))
+
+	When("it can't find the referenced instance", func() {
+		It("should update the status", func() {
+			By("creating a new AuraDatabaseUser")
+			ctx := context.Background()
+
+			user := &v1beta1.AuraDatabaseUser{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      userName,
+					Namespace: "default",
+				},
+				Spec: v1beta1.AuraDatabaseUserSpec{
+					InstanceRef: v1beta1.LocalObjectReference{
+						Name: instanceName,
+					},
+					Roles: []string{"reader"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, user)).Should(Succeed())
+
+			By("waiting for the reconciliation")
+			userLookupKey := types.NamespacedName{Name: userName, Namespace: "default"}
+			reconciledUser := &v1beta1.AuraDatabaseUser{}
+
+			expectedConditions := []metav1.Condition{
+				{
+					Type:    v1beta1.ConditionReady,
+					Status:  metav1.ConditionFalse,
+					Reason:  "ReconciliationFailed",
+					Message: fmt.Sprintf(`failed to get instance: AuraInstance.neo4j.infra.doodle.com "%s" not found`, instanceName),
+				},
+			}
+
+			Eventually(func() error {
+				err := k8sClient.Get(ctx, userLookupKey, reconciledUser)
+				if err != nil {
+					return err
+				}
+				return needsExactConditions(expectedConditions, reconciledUser.Status.Conditions)
+			}, timeout, interval).Should(Not(HaveOccurred()))
+
+			Expect(reconciledUser.Finalizers).To(ContainElement(v1beta1.Finalizer))
+		})
+	})
+
+	When("the instance is not running", func() {
+		It("should wait for the instance", func() {
+			By("creating the referenced AuraInstance")
+			ctx := context.Background()
+
+			instance := &v1beta1.AuraInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      instanceName,
+					Namespace: "default",
+				},
+				Spec: v1beta1.AuraInstanceSpec{
+					TenantID:      "x",
+					Neo4jVersion:  "5",
+					Tier:          "free-db",
+					CloudProvider: "gcp",
+					Suspend:       true,
+				},
+			}
+			Expect(k8sClient.Create(ctx, instance)).Should(Succeed())
+
+			By("waiting for the reconciliation")
+			userLookupKey := types.NamespacedName{Name: userName, Namespace: "default"}
+			reconciledUser := &v1beta1.AuraDatabaseUser{}
+
+			expectedConditions := []metav1.Condition{
+				{
+					Type:    v1beta1.ConditionReady,
+					Status:  metav1.ConditionFalse,
+					Reason:  "InstanceNotReady",
+					Message: fmt.Sprintf("Instance %s is not running", instanceName),
+				},
+			}
+
+			Eventually(func() error {
+				err := k8sClient.Get(ctx, userLookupKey, reconciledUser)
+				if err != nil {
+					return err
+				}
+				return needsExactConditions(expectedConditions, reconciledUser.Status.Conditions)
+			}, timeout, interval).Should(Not(HaveOccurred()))
+		})
+	})
+
+	When("the user is deleted", func() {
+		It("should remove the finalizer if the instance is gone", func() {
+			ctx := context.Background()
+
+			By("deleting the AuraInstance")
+			Expect(k8sClient.Delete(ctx, &v1beta1.AuraInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      instanceName,
+					Namespace: "default",
+				},
+			})).Should(Succeed())
+
+			By("deleting the AuraDatabaseUser")
+			Expect(k8sClient.Delete(ctx, &v1beta1.AuraDatabaseUser{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      userName,
+					Namespace: "default",
+				},
+			})).Should(Succeed())
+
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: userName, Namespace: "default"}, &v1beta1.AuraDatabaseUser{})
+				return kerrors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("AuraDatabaseUser statements", func() {
+	queries := func(statements []statement) []string {
+		var q []string
+		for _, stmt := range statements {
+			q = append(q, stmt.query)
+		}
+		return q
+	}
+
+	It("creates the user with its roles", func() {
+		user := v1beta1.AuraDatabaseUser{
+			ObjectMeta: metav1.ObjectMeta{Name: "app"},
+			Spec: v1beta1.AuraDatabaseUserSpec{
+				Roles: []string{"reader"},
+			},
+		}
+
+		statements := planUserStatements(user, false, true, "secret")
+		Expect(queries(statements)).To(Equal([]string{
+			"CREATE USER `app` SET PLAINTEXT PASSWORD $password CHANGE NOT REQUIRED",
+			"GRANT ROLE `reader` TO `app`",
+		}))
+		Expect(statements[0].params).To(HaveKeyWithValue("password", "secret"))
+	})
+
+	It("grants privileges using a role dedicated to the user", func() {
+		user := v1beta1.AuraDatabaseUser{
+			ObjectMeta: metav1.ObjectMeta{Name: "app"},
+			Spec: v1beta1.AuraDatabaseUserSpec{
+				Username:   "my-app",
+				Privileges: []string{"MATCH {*} ON GRAPH neo4j"},
+			},
+		}
+
+		Expect(queries(planUserStatements(user, true, false, "secret"))).To(Equal([]string{
+			"CREATE ROLE `my-app_privileges` IF NOT EXISTS",
+			"GRANT MATCH {*} ON GRAPH neo4j TO `my-app_privileges`",
+			"GRANT ROLE `my-app_privileges` TO `my-app`",
+		}))
+	})
+
+	It("resets the password and revokes removed roles and privileges", func() {
+		user := v1beta1.AuraDatabaseUser{
+			ObjectMeta: metav1.ObjectMeta{Name: "app"},
+			Spec: v1beta1.AuraDatabaseUserSpec{
+				Roles:      []string{"reader"},
+				Privileges: []string{"MATCH {*} ON GRAPH neo4j"},
+			},
+			Status: v1beta1.AuraDatabaseUserStatus{
+				Roles:      []string{"editor", "app_privileges"},
+				Privileges: []string{"MATCH {*} ON GRAPH neo4j", "WRITE ON GRAPH neo4j"},
+			},
+		}
+
+		Expect(queries(planUserStatements(user, true, true, "secret"))).To(Equal([]string{
+			"ALTER USER `app` SET PLAINTEXT PASSWORD $password CHANGE NOT REQUIRED",
+			"CREATE ROLE `app_privileges` IF NOT EXISTS",
+			"GRANT MATCH {*} ON GRAPH neo4j TO `app_privileges`",
+			"REVOKE WRITE ON GRAPH neo4j FROM `app_privileges`",
+			"GRANT ROLE `reader` TO `app`",
+			"GRANT ROLE `app_privileges` TO `app`",
+			"REVOKE ROLE `editor` FROM `app`",
+		}))
+	})
+
+	It("drops the role once all privileges are removed", func() {
+		user := v1beta1.AuraDatabaseUser{
+			ObjectMeta: metav1.ObjectMeta{Name: "app"},
+			Status: v1beta1.AuraDatabaseUserStatus{
+				Roles:      []string{"app_privileges"},
+				Privileges: []string{"MATCH {*} ON GRAPH neo4j"},
+			},
+		}
+
+		Expect(queries(planUserStatements(user, true, false, "secret"))).To(Equal([]string{
+			"REVOKE ROLE `app_privileges` FROM `app`",
+			"DROP ROLE `app_privileges` IF EXISTS",
+		}))
+		Expect(queries(planDropUserStatements(user))).To(Equal([]string{
+			"DROP USER `app` IF EXISTS",
+			"DROP ROLE `app_privileges` IF EXISTS",
+		}))
+	})
+
+	It("drops the previous user once the user is renamed", func() {
+		user := v1beta1.AuraDatabaseUser{
+			ObjectMeta: metav1.ObjectMeta{Name: "app"},
+			Spec: v1beta1.AuraDatabaseUserSpec{
+				Username:   "my-app",
+				Roles:      []string{"reader"},
+				Privileges: []string{"MATCH {*} ON GRAPH neo4j"},
+			},
+			Status: v1beta1.AuraDatabaseUserStatus{
+				Username:   "app",
+				Roles:      []string{"editor", "app_privileges"},
+				Privileges: []string{"WRITE ON GRAPH neo4j"},
+			},
+		}
+
+		Expect(queries(planUserStatements(user, false, true, "secret"))).To(Equal([]string{
+			"CREATE USER `my-app` SET PLAINTEXT PASSWORD $password CHANGE NOT REQUIRED",
+			"CREATE ROLE `my-app_privileges` IF NOT EXISTS",
+			"GRANT MATCH {*} ON GRAPH neo4j TO `my-app_privileges`",
+			"GRANT ROLE `reader` TO `my-app`",
+			"GRANT ROLE `my-app_privileges` TO `my-app`",
+			"DROP USER `app` IF EXISTS",
+			"DROP ROLE `app_privileges` IF EXISTS",
+		}))
+	})
+
+	It("only allows graph and database privileges", func() {
+		Expect(validatePrivileges([]string{
+			"MATCH {*} ON GRAPH neo4j",
+			"TRAVERSE ON GRAPH * NODES Person",
+			"read {name, `e-mail`} on graphs neo4j, movies relationships KNOWS",
+			"WRITE ON HOME GRAPH",
+			"SET LABEL Active ON GRAPH neo4j",
+			"ACCESS ON DATABASE neo4j",
+			"CREATE INDEX ON DATABASES *",
+			"CREATE NEW NODE LABEL ON HOME DATABASE",
+		})).To(Succeed())
+
+		for _, privilege := range []string{
+			"MATCH {*} ON GRAPH neo4j TO x; CREATE USER admin SET PASSWORD 'x'",
+			"MATCH {*} ON DBMS TO admin //",
+			"MATCH {*} ON GRAPH neo4j TO admin",
+			"ALL DBMS PRIVILEGES ON DBMS",
+			"ROLE MANAGEMENT ON DBMS",
+			"IMPERSONATE (neo4j) ON DBMS",
+			"ACCESS ON GRAPH neo4j",
+			"",
+		} {
+			Expect(validatePrivileges([]string{privilege})).NotTo(Succeed(), privilege)
+		}
+	})
+
+	It("rejects the admin user and built-in roles as username", func() {
+		Expect(validateUsername("app", "neo4j")).To(Succeed())
+		Expect(validateUsername("neo4j", "neo4j")).NotTo(Succeed())
+		Expect(validateUsername("admin", "neo4j")).NotTo(Succeed())
+		Expect(validateUsername("public", "neo4j")).NotTo(Succeed())
+		Expect(validateUsername("reader", "neo4j")).NotTo(Succeed())
+	})
+})
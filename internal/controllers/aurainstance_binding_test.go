@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// projectBinding resolves the provisioned service like a Service Binding implementation does
+// and returns the files which would be mounted into the workload at $SERVICE_BINDING_ROOT/<binding>.
+func projectBinding(ctx context.Context, instance v1beta1.AuraInstance, bindingName string) (map[string]string, error) {
+	if instance.Status.Binding == nil || instance.Status.Binding.Name == "" {
+		return nil, fmt.Errorf("%s is not a provisioned service", instance.Name)
+	}
+
+	var secret corev1.Secret
+	if err := k8sClient.Get(ctx, types.NamespacedName{
+		Name:      instance.Status.Binding.Name,
+		Namespace: instance.Namespace,
+	}, &secret); err != nil {
+		return nil, err
+	}
+
+	if _, ok := secret.Data["type"]; !ok {
+		return nil, fmt.Errorf("binding secret %s has no type entry", secret.Name)
+	}
+
+	files := make(map[string]string, len(secret.Data))
+	for key, value := range secret.Data {
+		files[filepath.Join("/bindings", bindingName, key)] = string(value)
+	}
+
+	return files, nil
+}
+
+var _ = Describe("AuraInstance Service Binding", func() {
+	It("only exposes templated connection secrets which define the type", func() {
+		instance := v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "instance"},
+		}
+
+		setBinding(&instance)
+		Expect(instance.Status.Binding).To(Equal(&v1beta1.LocalObjectReference{Name: "instance-connection"}))
+
+		instance.Spec.ConnectionSecret.Template = &v1beta1.ConnectionSecretTemplate{
+			Data: map[string]string{"NEO4J_URI": "{{ .ConnectionURL }}"},
+		}
+
+		setBinding(&instance)
+		Expect(instance.Status.Binding).To(BeNil())
+
+		instance.Spec.ConnectionSecret.Template.Data["type"] = "neo4j"
+		setBinding(&instance)
+		Expect(instance.Status.Binding).NotTo(BeNil())
+	})
+
+	It("can be consumed by a ServiceBinding", func() {
+		ctx := context.Background()
+		name := fmt.Sprintf("binding-%s", rand.String(5))
+
+		instance := &v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1beta1.AuraInstanceSpec{
+				TenantID:      "x",
+				Neo4jVersion:  "5",
+				Tier:          "free-db",
+				CloudProvider: "gcp",
+				Suspend:       true,
+			},
+		}
+		Expect(k8sClient.Create(ctx, instance)).Should(Succeed())
+
+		r := &AuraInstanceReconciler{
+			Client:   k8sClient,
+			Recorder: record.NewFakeRecorder(10),
+		}
+
+		Expect(r.writeCredentialsBackup(ctx, *instance, "neo4j", "secret", "neo4j+s://abc.databases.neo4j.io")).To(Succeed())
+		Expect(r.reconcileConnectionSecret(ctx, *instance, "neo4j+s://abc.databases.neo4j.io", ctrl.Log)).To(Succeed())
+		setBinding(instance)
+
+		files, err := projectBinding(ctx, *instance, "neo4j")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveKeyWithValue("/bindings/neo4j/type", "neo4j"))
+		Expect(files).To(HaveKeyWithValue("/bindings/neo4j/provider", "neo4j-aura"))
+		Expect(files).To(HaveKeyWithValue("/bindings/neo4j/host", "abc.databases.neo4j.io"))
+		Expect(files).To(HaveKeyWithValue("/bindings/neo4j/port", "7687"))
+		Expect(files).To(HaveKeyWithValue("/bindings/neo4j/uri", "neo4j+s://abc.databases.neo4j.io"))
+		Expect(files).To(HaveKeyWithValue("/bindings/neo4j/username", "neo4j"))
+		Expect(files).To(HaveKeyWithValue("/bindings/neo4j/password", "secret"))
+	})
+})
@@ -0,0 +1,224 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	infrav1beta1 "github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/bolt"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// script is a Cypher script loaded from a ConfigMap
+type script struct {
+	name    string
+	content string
+}
+
+// bootstrapScripts loads the bootstrap scripts in the order they are executed
+func (r *AuraInstanceReconciler) bootstrapScripts(ctx context.Context, instance infrav1beta1.AuraInstance) ([]script, error) {
+	var scripts []script
+	for _, ref := range instance.Spec.Bootstrap.ConfigMaps {
+		var configMap corev1.ConfigMap
+		if err := r.Get(ctx, types.NamespacedName{
+			Name:      ref.Name,
+			Namespace: instance.Namespace,
+		}, &configMap); err != nil {
+			return nil, err
+		}
+
+		keys := make([]string, 0, len(configMap.Data))
+		for key := range configMap.Data {
+			keys = append(keys, key)
+		}
+
+		slices.Sort(keys)
+		for _, key := range keys {
+			scripts = append(scripts, script{
+				name:    fmt.Sprintf("%s/%s", ref.Name, key),
+				content: configMap.Data[key],
+			})
+		}
+	}
+
+	return scripts, nil
+}
+
+// pendingScripts returns the scripts which have not been applied yet
+// and the names of applied scripts which have been changed since.
+func pendingScripts(scripts []script, applied []infrav1beta1.AppliedScript) ([]script, []string) {
+	var (
+		pending []script
+		changed []string
+	)
+
+	for _, s := range scripts {
+		idx := slices.IndexFunc(applied, func(a infrav1beta1.AppliedScript) bool {
+			return a.Name == s.name
+		})
+
+		switch {
+		case idx == -1:
+			pending = append(pending, s)
+		case applied[idx].Checksum != bolt.Checksum(s.content):
+			changed = append(changed, s.name)
+		}
+	}
+
+	return pending, changed
+}
+
+// bootstrapRetryInterval is the interval at which failed bootstrap scripts are retried
+const bootstrapRetryInterval = 30 * time.Second
+
+// restoreAppliedScripts adds the scripts recorded in the bootstrap annotation which are missing in the status
+func restoreAppliedScripts(instance *infrav1beta1.AuraInstance) error {
+	value, ok := instance.GetAnnotations()[infrav1beta1.BootstrapAnnotation]
+	if !ok {
+		return nil
+	}
+
+	var recorded []infrav1beta1.AppliedScript
+	if err := json.Unmarshal([]byte(value), &recorded); err != nil {
+		return fmt.Errorf("failed to parse %s annotation: %w", infrav1beta1.BootstrapAnnotation, err)
+	}
+
+	for _, applied := range recorded {
+		if !slices.ContainsFunc(instance.Status.Bootstrap, func(a infrav1beta1.AppliedScript) bool {
+			return a.Name == applied.Name
+		}) {
+			instance.Status.Bootstrap = append(instance.Status.Bootstrap, applied)
+		}
+	}
+
+	return nil
+}
+
+// recordAppliedScripts writes the applied scripts to the bootstrap annotation
+func (r *AuraInstanceReconciler) recordAppliedScripts(ctx context.Context, instance *infrav1beta1.AuraInstance) error {
+	value, err := json.Marshal(instance.Status.Bootstrap)
+	if err != nil {
+		return err
+	}
+
+	annotation := string(value)
+	if err := r.patchAnnotations(ctx, instance, map[string]*string{infrav1beta1.BootstrapAnnotation: &annotation}); err != nil {
+		return fmt.Errorf("failed to record applied bootstrap scripts: %w", err)
+	}
+
+	return nil
+}
+
+// reconcileBootstrap executes the bootstrap scripts which have not been applied yet.
+// Applied scripts are recorded with their checksum in the status and the bootstrap annotation and are never executed again.
+// Failures are reported in the Bootstrapped condition and retried after the returned interval,
+// they don't block the reconciliation of the instance.
+func (r *AuraInstanceReconciler) reconcileBootstrap(ctx context.Context, instance infrav1beta1.AuraInstance, logger logr.Logger) (infrav1beta1.AuraInstance, time.Duration) {
+	if instance.Spec.Bootstrap == nil || len(instance.Spec.Bootstrap.ConfigMaps) == 0 {
+		conditions.Delete(&instance, infrav1beta1.ConditionBootstrapped)
+		return instance, 0
+	}
+
+	if r.isDryRun(instance) {
+		return instance, 0
+	}
+
+	fail := func(reason string, err error) (infrav1beta1.AuraInstance, time.Duration) {
+		if conditions.GetMessage(&instance, infrav1beta1.ConditionBootstrapped) != err.Error() {
+			logger.Error(err, "bootstrap failed")
+			r.Recorder.Event(&instance, "Warning", "BootstrapFailed", err.Error())
+		}
+
+		return infrav1beta1.AuraInstanceBootstrapped(instance, metav1.ConditionFalse, reason, err.Error()), bootstrapRetryInterval
+	}
+
+	if err := restoreAppliedScripts(&instance); err != nil {
+		return fail("InvalidAnnotation", err)
+	}
+
+	scripts, err := r.bootstrapScripts(ctx, instance)
+	if kerrors.IsNotFound(err) {
+		instance = infrav1beta1.AuraInstanceBootstrapped(instance, metav1.ConditionFalse, "ConfigMapNotFound", err.Error())
+		return instance, 0
+	}
+
+	if err != nil {
+		return fail("ConfigMapFailed", fmt.Errorf("failed to get bootstrap scripts: %w", err))
+	}
+
+	pending, changed := pendingScripts(scripts, instance.Status.Bootstrap)
+	if len(pending) > 0 {
+		conn, _, err := dialInstance(ctx, r.Client, r.BoltDialer, instance)
+		if errors.Is(err, errCredentialsUnavailable) {
+			instance = infrav1beta1.AuraInstanceBootstrapped(instance, metav1.ConditionFalse, "CredentialsUnavailable", err.Error())
+			return instance, 0
+		}
+
+		if err != nil {
+			return fail("ConnectionFailed", err)
+		}
+
+		defer func() {
+			_ = conn.Close(ctx)
+		}()
+
+		for _, s := range pending {
+			logger.Info("applying bootstrap script", "script", s.name)
+
+			var statements []statement
+			for _, query := range bolt.SplitStatements(s.content) {
+				statements = append(statements, statement{query: query})
+			}
+
+			if err := runStatements(ctx, conn, instance.Spec.Bootstrap.Database, statements); err != nil {
+				return fail("ScriptFailed", fmt.Errorf("bootstrap script %s failed: %w", s.name, err))
+			}
+
+			instance.Status.Bootstrap = append(instance.Status.Bootstrap, infrav1beta1.AppliedScript{
+				Name:      s.name,
+				Checksum:  bolt.Checksum(s.content),
+				AppliedAt: metav1.Now(),
+			})
+
+			r.Recorder.Event(&instance, "Normal", "BootstrapScriptApplied", fmt.Sprintf("Applied bootstrap script %s", s.name))
+
+			if err := r.recordAppliedScripts(ctx, &instance); err != nil {
+				return fail("ScriptNotRecorded", err)
+			}
+		}
+	}
+
+	if len(changed) > 0 {
+		instance = infrav1beta1.AuraInstanceBootstrapped(instance, metav1.ConditionFalse, "ScriptChanged", fmt.Sprintf("Bootstrap scripts changed after they were applied and are not executed again: %s", strings.Join(changed, ", ")))
+		return instance, 0
+	}
+
+	instance = infrav1beta1.AuraInstanceBootstrapped(instance, metav1.ConditionTrue, "ScriptsApplied", fmt.Sprintf("Applied %d bootstrap scripts", len(instance.Status.Bootstrap)))
+	return instance, 0
+}
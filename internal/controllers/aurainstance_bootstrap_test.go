@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/bolt"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("AuraInstance bootstrap", func() {
+	It("returns scripts which are not applied yet", func() {
+		scripts := []script{
+			{name: "schema/01.cypher", content: "CREATE INDEX"},
+			{name: "schema/02.cypher", content: "CREATE CONSTRAINT"},
+			{name: "seed/data.cypher", content: "MERGE (n)"},
+		}
+
+		pending, changed := pendingScripts(scripts, []v1beta1.AppliedScript{
+			{Name: "schema/01.cypher", Checksum: bolt.Checksum("CREATE INDEX")},
+			{Name: "schema/02.cypher", Checksum: bolt.Checksum("DROP CONSTRAINT")},
+		})
+
+		Expect(pending).To(Equal([]script{{name: "seed/data.cypher", content: "MERGE (n)"}}))
+		Expect(changed).To(Equal([]string{"schema/02.cypher"}))
+	})
+
+	It("applies the scripts once", func() {
+		ctx := context.Background()
+		name := fmt.Sprintf("bootstrap-%s", rand.String(5))
+
+		instance := v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1beta1.AuraInstanceSpec{
+				TenantID:      "x",
+				Neo4jVersion:  "5",
+				Tier:          "free-db",
+				CloudProvider: "gcp",
+				Suspend:       true,
+				Bootstrap: &v1beta1.Bootstrap{
+					ConfigMaps: []v1beta1.LocalObjectReference{{Name: name}},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, &instance)).Should(Succeed())
+
+		dialer := &fakeDialer{password: "secret"}
+		r := &AuraInstanceReconciler{
+			Client:     k8sClient,
+			Recorder:   record.NewFakeRecorder(10),
+			BoltDialer: dialer,
+		}
+
+		By("waiting for the ConfigMap")
+		instance, retryAfter := r.reconcileBootstrap(ctx, instance, ctrl.Log)
+		Expect(retryAfter).To(BeZero())
+		Expect(conditions.GetReason(&instance, v1beta1.ConditionBootstrapped)).To(Equal("ConfigMapNotFound"))
+
+		Expect(k8sClient.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Data: map[string]string{
+				"02-seed.cypher":   "MERGE (:Config {key: 'a'});\nMERGE (:Config {key: 'b'});",
+				"01-schema.cypher": "CREATE INDEX config_key IF NOT EXISTS FOR (c:Config) ON (c.key)",
+			},
+		})).Should(Succeed())
+
+		By("waiting for the credentials backup")
+		instance, retryAfter = r.reconcileBootstrap(ctx, instance, ctrl.Log)
+		Expect(retryAfter).To(BeZero())
+		Expect(conditions.GetReason(&instance, v1beta1.ConditionBootstrapped)).To(Equal("CredentialsUnavailable"))
+
+		Expect(k8sClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      credentialsBackupName(instance),
+				Namespace: "default",
+			},
+			StringData: map[string]string{
+				"username":      "neo4j",
+				"password":      "secret",
+				"connectionURL": "neo4j+s://abc.databases.neo4j.io",
+			},
+		})).Should(Succeed())
+
+		By("retrying a failed script")
+		dialer.queryErrs = map[string]error{"MERGE (:Config {key: 'b'})": errors.New("constraint violation")}
+		instance, retryAfter = r.reconcileBootstrap(ctx, instance, ctrl.Log)
+		Expect(retryAfter).To(Equal(bootstrapRetryInterval))
+		Expect(conditions.GetReason(&instance, v1beta1.ConditionBootstrapped)).To(Equal("ScriptFailed"))
+		Expect(conditions.GetMessage(&instance, v1beta1.ConditionBootstrapped)).To(ContainSubstring("constraint violation"))
+		Expect(instance.Status.Bootstrap).To(HaveLen(1))
+
+		By("applying the scripts")
+		dialer.queryErrs = nil
+		instance, retryAfter = r.reconcileBootstrap(ctx, instance, ctrl.Log)
+		Expect(retryAfter).To(BeZero())
+		Expect(conditions.IsTrue(&instance, v1beta1.ConditionBootstrapped)).To(BeTrue())
+		Expect(dialer.queries).To(Equal([]string{
+			"CREATE INDEX config_key IF NOT EXISTS FOR (c:Config) ON (c.key)",
+			"MERGE (:Config {key: 'a'})",
+			"MERGE (:Config {key: 'b'})",
+			"MERGE (:Config {key: 'a'})",
+			"MERGE (:Config {key: 'b'})",
+		}))
+		Expect(instance.Status.Bootstrap).To(HaveLen(2))
+		Expect(instance.Status.Bootstrap[0].Name).To(Equal(name + "/01-schema.cypher"))
+
+		By("not applying the scripts again")
+		instance, retryAfter = r.reconcileBootstrap(ctx, instance, ctrl.Log)
+		Expect(retryAfter).To(BeZero())
+		Expect(dialer.queries).To(HaveLen(5))
+
+		By("not applying the scripts again if the status is lost")
+		var restored v1beta1.AuraInstance
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&instance), &restored)).Should(Succeed())
+		Expect(restored.Annotations).To(HaveKey(v1beta1.BootstrapAnnotation))
+		restored.Status = v1beta1.AuraInstanceStatus{}
+
+		restored, retryAfter = r.reconcileBootstrap(ctx, restored, ctrl.Log)
+		Expect(retryAfter).To(BeZero())
+		Expect(conditions.IsTrue(&restored, v1beta1.ConditionBootstrapped)).To(BeTrue())
+		Expect(restored.Status.Bootstrap).To(HaveLen(2))
+		for i, applied := range restored.Status.Bootstrap {
+			Expect(applied.Name).To(Equal(instance.Status.Bootstrap[i].Name))
+			Expect(applied.Checksum).To(Equal(instance.Status.Bootstrap[i].Checksum))
+		}
+		Expect(dialer.queries).To(HaveLen(5))
+	})
+})
@@ -0,0 +1,301 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"text/template"
+
+	infrav1beta1 "github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// defaultBoltPort is used if the connection url does not contain a port
+	defaultBoltPort = "7687"
+
+	credentialsUsernameKey      = "username"
+	credentialsPasswordKey      = "password"
+	credentialsConnectionURLKey = "connectionURL"
+
+	// Well-known entries of the Service Binding for Kubernetes specification
+	bindingTypeKey     = "type"
+	bindingProviderKey = "provider"
+	bindingHostKey     = "host"
+	bindingPortKey     = "port"
+	bindingURIKey      = "uri"
+	bindingType        = "neo4j"
+	bindingProvider    = "neo4j-aura"
+)
+
+// connectionSecretName returns the name of the secret which contains the connection details
+func connectionSecretName(instance infrav1beta1.AuraInstance) string {
+	if instance.Spec.ConnectionSecret.Name != "" {
+		return instance.Spec.ConnectionSecret.Name
+	}
+
+	return fmt.Sprintf("%s-connection", instance.Name)
+}
+
+// bindingSecretName returns the name of the connection secret if it can be consumed as Service Binding.
+// A templated connection secret is only a binding secret if the template defines the type entry.
+func bindingSecretName(instance infrav1beta1.AuraInstance) string {
+	if instance.Spec.ConnectionSecret.Template != nil {
+		if _, ok := instance.Spec.ConnectionSecret.Template.Data[bindingTypeKey]; !ok {
+			return ""
+		}
+	}
+
+	return connectionSecretName(instance)
+}
+
+// setBinding exposes the connection secret as Service Binding if it contains the well-known entries
+func setBinding(instance *infrav1beta1.AuraInstance) {
+	name := bindingSecretName(*instance)
+	if name == "" {
+		instance.Status.Binding = nil
+		return
+	}
+
+	instance.Status.Binding = &infrav1beta1.LocalObjectReference{Name: name}
+}
+
+// credentialsBackupName returns the name of the controller owned secret which backs up the instance credentials.
+// Aura returns the password only once, the backup allows to rebuild the connection secret.
+func credentialsBackupName(instance infrav1beta1.AuraInstance) string {
+	return fmt.Sprintf("%s-credentials-backup", instance.Name)
+}
+
+// connectionDetails is the data available to connection secret templates
+type connectionDetails struct {
+	InstanceID    string
+	Name          string
+	TenantID      string
+	ConnectionURL string
+	Scheme        string
+	Host          string
+	Port          string
+	Username      string
+	Password      string
+	Region        string
+	CloudProvider string
+	Tier          string
+	Version       string
+}
+
+// newConnectionDetails builds the template data from the instance and its credentials
+func newConnectionDetails(instance infrav1beta1.AuraInstance, username, password, connectionURL string) (connectionDetails, error) {
+	details := connectionDetails{
+		InstanceID:    instance.Status.InstanceID,
+		Name:          instance.Name,
+		TenantID:      instance.Spec.TenantID,
+		ConnectionURL: connectionURL,
+		Username:      username,
+		Password:      password,
+		Region:        instance.Spec.Region,
+		CloudProvider: string(instance.Spec.CloudProvider),
+		Tier:          string(instance.Spec.Tier),
+		Version:       instance.Spec.Neo4jVersion,
+	}
+
+	u, err := url.Parse(connectionURL)
+	if err != nil {
+		return details, fmt.Errorf("failed to parse connection url: %w", err)
+	}
+
+	details.Scheme = u.Scheme
+	details.Host = u.Hostname()
+	details.Port = u.Port()
+	if details.Port == "" {
+		details.Port = defaultBoltPort
+	}
+
+	return details, nil
+}
+
+// renderConnectionSecret renders the connection secret contents.
+// Without a template the secret contains the keys username, password and connectionURL.
+func renderConnectionSecret(instance infrav1beta1.AuraInstance, details connectionDetails) (map[string]string, error) {
+	if instance.Spec.ConnectionSecret.Template == nil {
+		return defaultConnectionSecretData(details), nil
+	}
+
+	data := make(map[string]string, len(instance.Spec.ConnectionSecret.Template.Data))
+	for key, value := range instance.Spec.ConnectionSecret.Template.Data {
+		tmpl, err := template.New(key).Option("missingkey=error").Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse connection secret template for key %s: %w", key, err)
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, details); err != nil {
+			return nil, fmt.Errorf("failed to render connection secret template for key %s: %w", key, err)
+		}
+
+		data[key] = buf.String()
+	}
+
+	return data, nil
+}
+
+// defaultConnectionSecretData returns the connection secret contents used without a template.
+// Besides the credentials it contains the well-known Service Binding entries.
+func defaultConnectionSecretData(details connectionDetails) map[string]string {
+	return map[string]string{
+		credentialsUsernameKey:      details.Username,
+		credentialsPasswordKey:      details.Password,
+		credentialsConnectionURLKey: details.ConnectionURL,
+		bindingTypeKey:              bindingType,
+		bindingProviderKey:          bindingProvider,
+		bindingHostKey:              details.Host,
+		bindingPortKey:              details.Port,
+		bindingURIKey:               details.ConnectionURL,
+	}
+}
+
+// connectionSecretData renders the connection secret contents.
+// The default keys are returned alongside the error if the template can't be rendered.
+func connectionSecretData(instance infrav1beta1.AuraInstance, username, password, connectionURL string) (map[string]string, error) {
+	details, err := newConnectionDetails(instance, username, password, connectionURL)
+	if err != nil {
+		return defaultConnectionSecretData(details), err
+	}
+
+	data, err := renderConnectionSecret(instance, details)
+	if err != nil {
+		return defaultConnectionSecretData(details), err
+	}
+
+	return data, nil
+}
+
+// connectionSecretCredentialKeys returns the keys of the connection secret which contain the plain username, password and connection url.
+// Entries of a templated secret are located by rendering the template with placeholders, keys which don't exist are empty.
+func connectionSecretCredentialKeys(instance infrav1beta1.AuraInstance) (string, string, string) {
+	if instance.Spec.ConnectionSecret.Template == nil {
+		return credentialsUsernameKey, credentialsPasswordKey, credentialsConnectionURLKey
+	}
+
+	placeholders := connectionDetails{
+		Username:      "{username}",
+		Password:      "{password}",
+		ConnectionURL: "{connectionURL}",
+	}
+
+	data, err := renderConnectionSecret(instance, placeholders)
+	if err != nil {
+		return "", "", ""
+	}
+
+	keys := slices.Sorted(maps.Keys(data))
+	find := func(placeholder string) string {
+		for _, key := range keys {
+			if data[key] == placeholder {
+				return key
+			}
+		}
+
+		return ""
+	}
+
+	return find(placeholders.Username), find(placeholders.Password), find(placeholders.ConnectionURL)
+}
+
+// validateConnectionSecretTemplate renders the template with empty data to detect errors
+// before the instance is created, the initial credentials are only returned once.
+func validateConnectionSecretTemplate(instance infrav1beta1.AuraInstance) error {
+	_, err := renderConnectionSecret(instance, connectionDetails{})
+	return err
+}
+
+// renderConnectionSecretFromBackup renders the connection secret using the backed up credentials
+func renderConnectionSecretFromBackup(instance infrav1beta1.AuraInstance, backup corev1.Secret, connectionURL string) (map[string]string, error) {
+	details, err := newConnectionDetails(instance, string(backup.Data[credentialsUsernameKey]), string(backup.Data[credentialsPasswordKey]), connectionURL)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := renderConnectionSecret(instance, details)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render connection secret: %w", err)
+	}
+
+	return data, nil
+}
+
+// credentialIndependentData renders the connection secret keys which don't contain any credentials.
+// These keys can be restored even if the credentials are lost.
+func credentialIndependentData(instance infrav1beta1.AuraInstance, connectionURL string) (map[string]string, error) {
+	first, err := newConnectionDetails(instance, "user-a", "password-a", connectionURL)
+	if err != nil {
+		return nil, err
+	}
+
+	second, err := newConnectionDetails(instance, "user-b", "password-b", connectionURL)
+	if err != nil {
+		return nil, err
+	}
+
+	firstData, err := renderConnectionSecret(instance, first)
+	if err != nil {
+		return nil, err
+	}
+
+	secondData, err := renderConnectionSecret(instance, second)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]string)
+	for key, value := range firstData {
+		if secondData[key] == value {
+			data[key] = value
+		}
+	}
+
+	return data, nil
+}
+
+// repairConnectionSecretData returns the secret data which restores missing keys without touching existing ones
+func repairConnectionSecretData(current map[string][]byte, restorable map[string]string) map[string][]byte {
+	data := make(map[string][]byte, len(current)+len(restorable))
+	for key, value := range current {
+		data[key] = value
+	}
+
+	for key, value := range restorable {
+		if _, ok := data[key]; !ok {
+			data[key] = []byte(value)
+		}
+	}
+
+	return data
+}
+
+// secretData converts rendered secret contents into secret data
+func secretData(data map[string]string) map[string][]byte {
+	b := make(map[string][]byte, len(data))
+	for key, value := range data {
+		b[key] = []byte(value)
+	}
+
+	return b
+}
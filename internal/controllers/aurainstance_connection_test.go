@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("AuraInstance connection secret", func() {
+	newInstance := func() v1beta1.AuraInstance {
+		return v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "instance",
+			},
+			Spec: v1beta1.AuraInstanceSpec{
+				Tier:          v1beta1.AuraInstanceTierProfessionalDb,
+				Region:        "europe-west1",
+				CloudProvider: v1beta1.CloudProviderGCP,
+				Neo4jVersion:  "5",
+			},
+			Status: v1beta1.AuraInstanceStatus{
+				InstanceID: "abc",
+			},
+		}
+	}
+
+	It("uses the default keys without a template", func() {
+		data, err := connectionSecretData(newInstance(), "neo4j", "secret", "neo4j+s://abc.databases.neo4j.io")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(map[string]string{
+			"username":      "neo4j",
+			"password":      "secret",
+			"connectionURL": "neo4j+s://abc.databases.neo4j.io",
+			"type":          "neo4j",
+			"provider":      "neo4j-aura",
+			"host":          "abc.databases.neo4j.io",
+			"port":          "7687",
+			"uri":           "neo4j+s://abc.databases.neo4j.io",
+		}))
+	})
+
+	It("renders the template", func() {
+		instance := newInstance()
+		instance.Spec.ConnectionSecret.Template = &v1beta1.ConnectionSecretTemplate{
+			Data: map[string]string{
+				"NEO4J_URI":  "{{ .ConnectionURL }}",
+				"NEO4J_HOST": "{{ .Host }}:{{ .Port }}",
+				"BOLT_URL":   "bolt+s://{{ .Host }}",
+				"application.properties": "spring.neo4j.uri={{ .ConnectionURL }}\n" +
+					"spring.neo4j.authentication.username={{ .Username }}\n" +
+					"spring.neo4j.authentication.password={{ .Password }}\n" +
+					"# {{ .InstanceID }} {{ .Region }} {{ .Version }}",
+			},
+		}
+
+		data, err := connectionSecretData(instance, "neo4j", "secret", "neo4j+s://abc.databases.neo4j.io")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(map[string]string{
+			"NEO4J_URI":  "neo4j+s://abc.databases.neo4j.io",
+			"NEO4J_HOST": "abc.databases.neo4j.io:7687",
+			"BOLT_URL":   "bolt+s://abc.databases.neo4j.io",
+			"application.properties": "spring.neo4j.uri=neo4j+s://abc.databases.neo4j.io\n" +
+				"spring.neo4j.authentication.username=neo4j\n" +
+				"spring.neo4j.authentication.password=secret\n" +
+				"# abc europe-west1 5",
+		}))
+	})
+
+	It("keeps the port of the connection url", func() {
+		details, err := newConnectionDetails(newInstance(), "neo4j", "secret", "neo4j+s://abc.databases.neo4j.io:7688")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(details.Scheme).To(Equal("neo4j+s"))
+		Expect(details.Port).To(Equal("7688"))
+	})
+
+	It("falls back to the default keys if the template is invalid", func() {
+		instance := newInstance()
+		instance.Spec.ConnectionSecret.Template = &v1beta1.ConnectionSecretTemplate{
+			Data: map[string]string{
+				"NEO4J_URI": "{{ .Unknown }}",
+			},
+		}
+
+		Expect(validateConnectionSecretTemplate(instance)).To(HaveOccurred())
+
+		data, err := connectionSecretData(instance, "neo4j", "secret", "neo4j+s://abc.databases.neo4j.io")
+		Expect(err).To(HaveOccurred())
+		Expect(data).To(HaveKeyWithValue("password", "secret"))
+	})
+
+	It("defaults the connection secret name", func() {
+		instance := newInstance()
+		Expect(connectionSecretName(instance)).To(Equal("instance-connection"))
+		Expect(credentialsBackupName(instance)).To(Equal("instance-credentials-backup"))
+
+		instance.Spec.ConnectionSecret.Name = "custom"
+		Expect(connectionSecretName(instance)).To(Equal("custom"))
+	})
+
+	It("renders the connection secret from the credentials backup", func() {
+		data, err := renderConnectionSecretFromBackup(newInstance(), corev1.Secret{
+			Data: map[string][]byte{
+				"username": []byte("neo4j"),
+				"password": []byte("secret"),
+			},
+		}, "neo4j+s://abc.databases.neo4j.io")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(HaveKeyWithValue("password", "secret"))
+		Expect(data).To(HaveKeyWithValue("connectionURL", "neo4j+s://abc.databases.neo4j.io"))
+	})
+
+	It("only restores keys without credentials if the credentials are lost", func() {
+		instance := newInstance()
+		instance.Spec.ConnectionSecret.Template = &v1beta1.ConnectionSecretTemplate{
+			Data: map[string]string{
+				"NEO4J_URI":      "{{ .ConnectionURL }}",
+				"NEO4J_HOST":     "{{ .Host }}",
+				"NEO4J_PASSWORD": "{{ .Password }}",
+				"NEO4J_AUTH":     "{{ .Username }}/{{ .Password }}",
+			},
+		}
+
+		restorable, err := credentialIndependentData(instance, "neo4j+s://abc.databases.neo4j.io")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(restorable).To(Equal(map[string]string{
+			"NEO4J_URI":  "neo4j+s://abc.databases.neo4j.io",
+			"NEO4J_HOST": "abc.databases.neo4j.io",
+		}))
+
+		data := repairConnectionSecretData(map[string][]byte{
+			"NEO4J_HOST":     []byte("custom"),
+			"NEO4J_PASSWORD": []byte("secret"),
+		}, restorable)
+
+		Expect(data).To(Equal(map[string][]byte{
+			"NEO4J_URI":      []byte("neo4j+s://abc.databases.neo4j.io"),
+			"NEO4J_HOST":     []byte("custom"),
+			"NEO4J_PASSWORD": []byte("secret"),
+		}))
+	})
+})
@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
@@ -26,10 +27,17 @@ import (
 	"golang.org/x/oauth2/clientcredentials"
 
 	infrav1beta1 "github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/bolt"
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/metrics"
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/sink"
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/tracing"
 	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
+	"github.com/fluxcd/pkg/apis/meta"
 	"github.com/fluxcd/pkg/runtime/conditions"
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -38,6 +46,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -47,24 +56,41 @@ import (
 //+kubebuilder:rbac:groups=neo4j.infra.doodle.com,resources=aurainstances/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=neo4j.infra.doodle.com,resources=aurainstances/finalizers,verbs=update
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;delete;patch;update
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;delete;patch;update
+//+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;delete;patch;update
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=scrapeconfigs,verbs=get;list;watch;create;delete;patch;update
 
 // AuraInstanceReconciler reconciles an AuraInstance object
 type AuraInstanceReconciler struct {
 	client.Client
-	TokenURL   string
-	BaseURL    string
-	HTTPClient *http.Client
-	Log        logr.Logger
-	Recorder   record.EventRecorder
+	TokenURL        string
+	BaseURL         string
+	HTTPClient      *http.Client
+	Log             logr.Logger
+	Recorder        record.EventRecorder
+	DefaultInterval time.Duration
+	DryRun          bool
+	BoltDialer      bolt.Dialer
+
+	// ReplicationAllowedNamespaces lists the namespaces or glob patterns connection secrets may be replicated to
+	ReplicationAllowedNamespaces []string
 }
 
 type AuraInstanceReconcilerOptions struct {
 	MaxConcurrentReconciles int
 }
 
-const secretIndexKey = ".metadata.secret"
+const (
+	secretIndexKey    = ".metadata.secret"
+	configMapIndexKey = ".metadata.configMap"
+)
 
 func (r *AuraInstanceReconciler) SetupWithManager(mgr ctrl.Manager, opts AuraInstanceReconcilerOptions) error {
+	if r.BoltDialer == nil {
+		r.BoltDialer = bolt.NewDialer()
+	}
+
 	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &infrav1beta1.AuraInstance{}, secretIndexKey,
 		func(o client.Object) []string {
 			instance := o.(*infrav1beta1.AuraInstance)
@@ -76,6 +102,27 @@ func (r *AuraInstanceReconciler) SetupWithManager(mgr ctrl.Manager, opts AuraIns
 				}
 			}
 
+			if sink := instance.Spec.ConnectionSecret.Sink; sink != nil && sink.Vault != nil {
+				keys = append(keys, fmt.Sprintf("%s/%s", instance.GetNamespace(), sink.Vault.TokenSecret.Name))
+			}
+
+			return keys
+		},
+	); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &infrav1beta1.AuraInstance{}, configMapIndexKey,
+		func(o client.Object) []string {
+			instance := o.(*infrav1beta1.AuraInstance)
+			keys := []string{}
+
+			if instance.Spec.Bootstrap != nil {
+				for _, ref := range instance.Spec.Bootstrap.ConfigMaps {
+					keys = append(keys, fmt.Sprintf("%s/%s", instance.GetNamespace(), ref.Name))
+				}
+			}
+
 			return keys
 		},
 	); err != nil {
@@ -84,13 +131,24 @@ func (r *AuraInstanceReconciler) SetupWithManager(mgr ctrl.Manager, opts AuraIns
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&infrav1beta1.AuraInstance{}, builder.WithPredicates(
-			predicate.GenerationChangedPredicate{},
+			predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}),
 		)).
+		Owns(&corev1.Secret{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&corev1.Service{}).
 		WithOptions(controller.Options{MaxConcurrentReconciles: opts.MaxConcurrentReconciles}).
 		Watches(
 			&corev1.Secret{},
 			handler.EnqueueRequestsFromMapFunc(r.requestsForSecretChange),
 		).
+		Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForConfigMapChange),
+		).
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForNamespaceChange),
+		).
 		Complete(r)
 }
 
@@ -108,6 +166,10 @@ func (r *AuraInstanceReconciler) requestsForSecretChange(ctx context.Context, o
 		panic(fmt.Sprintf("expected a Secret, got %T", o))
 	}
 
+	if reqs := requestsForReplica(secret); reqs != nil {
+		return reqs
+	}
+
 	var list infrav1beta1.AuraInstanceList
 	if err := r.List(ctx, &list, client.MatchingFields{
 		secretIndexKey: objectKey(secret).String(),
@@ -124,54 +186,151 @@ func (r *AuraInstanceReconciler) requestsForSecretChange(ctx context.Context, o
 	return reqs
 }
 
+func (r *AuraInstanceReconciler) requestsForConfigMapChange(ctx context.Context, o client.Object) []reconcile.Request {
+	configMap, ok := o.(*corev1.ConfigMap)
+	if !ok {
+		panic(fmt.Sprintf("expected a ConfigMap, got %T", o))
+	}
+
+	var list infrav1beta1.AuraInstanceList
+	if err := r.List(ctx, &list, client.MatchingFields{
+		configMapIndexKey: objectKey(configMap).String(),
+	}); err != nil {
+		return nil
+	}
+
+	var reqs []reconcile.Request
+	for _, instance := range list.Items {
+		r.Log.V(1).Info("referenced configmap from a AuraInstance changed detected", "namespace", instance.GetNamespace(), "name", instance.GetName())
+		reqs = append(reqs, reconcile.Request{NamespacedName: objectKey(&instance)})
+	}
+
+	return reqs
+}
+
 func (r *AuraInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := r.Log.WithValues("namespace", req.Namespace, "name", req.Name)
 
+	ctx = tracing.ContextWithAttributes(ctx,
+		tracing.AttributeNamespace.String(req.Namespace),
+		tracing.AttributeName.String(req.Name),
+	)
+
+	ctx, span := tracing.Start(ctx, "AuraInstance.Reconcile")
+	defer span.End()
+
 	instance := infrav1beta1.AuraInstance{}
 	err := r.Get(ctx, req.NamespacedName, &instance)
 	if err != nil {
 		if kerrors.IsNotFound(err) {
+			metrics.Delete(req.Namespace, req.Name)
 			return reconcile.Result{}, nil
 		}
+		tracing.RecordError(span, err)
 		return reconcile.Result{}, err
 	}
 
+	instanceAttributes := []attribute.KeyValue{
+		tracing.AttributeTenantID.String(instance.Spec.TenantID),
+		tracing.AttributeInstanceID.String(instance.Status.InstanceID),
+	}
+	span.SetAttributes(instanceAttributes...)
+	ctx = tracing.ContextWithAttributes(ctx, instanceAttributes...)
+
+	if !instance.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, instance, logger)
+	}
+
 	if instance.Spec.Suspend {
 		logger.Info("aura instance is suspended")
 		return ctrl.Result{}, nil
 	}
 
+	// Replicated and externally stored connection secrets can't be garbage collected
+	if needsFinalizer(instance) && !controllerutil.ContainsFinalizer(&instance, infrav1beta1.Finalizer) {
+		controllerutil.AddFinalizer(&instance, infrav1beta1.Finalizer)
+		if err := r.Update(ctx, &instance); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	logger.Info("reconciling aura instance")
+
+	// Stalled is set again by the reconciliation if the instance is still failed
+	conditions.Delete(&instance, infrav1beta1.ConditionStalled)
 	instance, result, err := r.reconcile(ctx, instance, logger)
-	instance.Status.ObservedGeneration = instance.GetGeneration()
+
+	// Reconcile requests are handled regardless of the outcome, retries are scheduled by the result
+	if requestedAt, ok := meta.ReconcileAnnotationValue(instance.GetAnnotations()); ok {
+		instance.Status.SetLastHandledReconcileRequest(requestedAt)
+	}
+
+	instance = setReconcileResult(instance, err)
 
 	if err != nil {
 		logger.Error(err, "reconcile error occurred")
-		instance = infrav1beta1.AuraInstanceReady(instance, metav1.ConditionFalse, "ReconciliationFailed", err.Error())
 		r.Recorder.Event(&instance, "Warning", "ReconciliationFailed", err.Error())
+		tracing.RecordError(span, err)
 	}
 
+	// The instance id is only known once the instance has been created
+	span.SetAttributes(tracing.AttributeInstanceID.String(instance.Status.InstanceID))
+	metrics.RecordReconcile(instance, err, time.Now())
+
 	// Update status after reconciliation
 	if err := r.patchStatus(ctx, &instance); err != nil {
 		logger.Error(err, "unable to update status after reconciliation")
 		return ctrl.Result{Requeue: true}, err
 	}
 
-	if err == nil && instance.Spec.Interval != nil {
-		result.RequeueAfter = instance.Spec.Interval.Duration
+	interval := r.DefaultInterval
+	if instance.Spec.Interval != nil {
+		interval = instance.Spec.Interval.Duration
+	}
+
+	if err == nil && !result.Requeue && interval > 0 && (result.RequeueAfter == 0 || result.RequeueAfter > interval) {
+		result.RequeueAfter = interval
 	}
 
 	return result, err
 }
 
-func (r *AuraInstanceReconciler) httpClient(ctx context.Context, instance infrav1beta1.AuraInstance) (*http.Client, error) {
-	var secret corev1.Secret
-	if err := r.Get(ctx, types.NamespacedName{
-		Name:      instance.Spec.Secret.Name,
-		Namespace: instance.Namespace,
-	}, &secret); err != nil {
-		return nil, fmt.Errorf("failed to get secret: %w", err)
+// needsFinalizer reports whether the instance has resources which are not garbage collected using owner references
+func needsFinalizer(instance infrav1beta1.AuraInstance) bool {
+	return instance.Spec.ConnectionSecret.ReplicateTo != nil || instance.Spec.ConnectionSecret.Sink != nil
+}
+
+// reconcileDelete removes the replicated connection secrets and the connection secret from external secret stores
+// before the instance is deleted. Secrets within the instance namespace are garbage collected using owner references.
+func (r *AuraInstanceReconciler) reconcileDelete(ctx context.Context, instance infrav1beta1.AuraInstance, logger logr.Logger) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(&instance, infrav1beta1.Finalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	err := r.deleteReplicas(ctx, instance, nil)
+	if err == nil {
+		err = r.deleteFromSinks(ctx, instance)
+
+		// The Vault token secret is commonly deleted together with the instance, waiting for it would block the deletion forever
+		if kerrors.IsNotFound(err) {
+			logger.Info("skipping connection secret removal from sinks", "error", err.Error())
+			r.Recorder.Event(&instance, "Warning", "SinkCleanupSkipped", fmt.Sprintf("Connection secret is not removed from external secret stores: %s", err))
+			err = nil
+		}
+	}
+
+	if err != nil {
+		logger.Error(err, "failed to clean up connection secrets")
+		r.Recorder.Event(&instance, "Warning", "DeletionFailed", err.Error())
+		return ctrl.Result{}, err
 	}
+
+	controllerutil.RemoveFinalizer(&instance, infrav1beta1.Finalizer)
+	return ctrl.Result{}, r.Update(ctx, &instance)
+}
+
+// credentialKeys returns the keys of the Aura API client id and secret in the instance secret
+func credentialKeys(instance infrav1beta1.AuraInstance) (string, string) {
 	clientIDKey := instance.Spec.Secret.ClientIDKey
 	if clientIDKey == "" {
 		clientIDKey = "clientID"
@@ -180,6 +339,19 @@ func (r *AuraInstanceReconciler) httpClient(ctx context.Context, instance infrav
 	if clientSecretKey == "" {
 		clientSecretKey = "clientSecret"
 	}
+
+	return clientIDKey, clientSecretKey
+}
+
+func (r *AuraInstanceReconciler) httpClient(ctx context.Context, instance infrav1beta1.AuraInstance) (*http.Client, error) {
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{
+		Name:      instance.Spec.Secret.Name,
+		Namespace: instance.Namespace,
+	}, &secret); err != nil {
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+	clientIDKey, clientSecretKey := credentialKeys(instance)
 	clientID := string(secret.Data[clientIDKey])
 	clientSecret := string(secret.Data[clientSecretKey])
 	if clientID == "" || clientSecret == "" {
@@ -215,10 +387,7 @@ func (r *AuraInstanceReconciler) reconcile(ctx context.Context, instance infrav1
 		return instance, reconcile.Result{}, fmt.Errorf("failed to create aura client: %w", err)
 	}
 
-	connectionSecretName := fmt.Sprintf("%s-connection", instance.Name)
-	if instance.Spec.ConnectionSecret.Name != "" {
-		connectionSecretName = instance.Spec.ConnectionSecret.Name
-	}
+	connectionSecretName := connectionSecretName(instance)
 
 	if instance.Status.InstanceID != "" {
 		auraInstance, err := auraClient.GetInstanceIdWithResponse(ctx, instance.Status.InstanceID)
@@ -228,28 +397,31 @@ func (r *AuraInstanceReconciler) reconcile(ctx context.Context, instance infrav1
 		}
 
 		if auraInstance.StatusCode() == http.StatusNotFound {
-			var secret corev1.Secret
-			err := r.Get(ctx, types.NamespacedName{
-				Name:      connectionSecretName,
-				Namespace: instance.Namespace,
-			}, &secret)
-
-			if err != nil && !kerrors.IsNotFound(err) {
-				return instance, reconcile.Result{}, fmt.Errorf("failed to get secret: %w", err)
-			} else if err == nil {
-				if len(secret.OwnerReferences) > 0 {
-					if secret.OwnerReferences[0].UID != instance.UID {
-						return instance, reconcile.Result{}, fmt.Errorf("failed to delete secret, owner uid %s does not match", secret.OwnerReferences[0].UID)
-					}
+			for _, name := range []string{connectionSecretName, credentialsBackupName(instance)} {
+				if err := r.deleteSecret(ctx, instance, name); err != nil {
+					return instance, reconcile.Result{}, err
 				}
+			}
 
-				if err := r.Delete(ctx, &secret); err != nil {
-					return instance, reconcile.Result{}, fmt.Errorf("failed to delete secret: %w", err)
-				}
+			instance, err = r.deleteOutputs(ctx, instance)
+			if err != nil {
+				return instance, reconcile.Result{}, err
+			}
+
+			if err := r.deleteReplicas(ctx, instance, nil); err != nil {
+				return instance, reconcile.Result{}, err
 			}
 
+			if err := r.deleteFromSinks(ctx, instance); err != nil {
+				return instance, reconcile.Result{}, err
+			}
+			instance.Status.ReplicatedTo = nil
+
 			instance.Status.InstanceID = ""
 			instance.Status.ConnectionSecret = ""
+			clearInstanceDetails(&instance)
+			instance.Status.Binding = nil
+			setPlan(&instance, nil)
 
 			return instance, reconcile.Result{Requeue: true}, nil
 		}
@@ -258,44 +430,75 @@ func (r *AuraInstanceReconciler) reconcile(ctx context.Context, instance infrav1
 			return instance, reconcile.Result{}, fmt.Errorf("failed to get instance, request failed with code %d - %s", auraInstance.StatusCode(), auraInstance.Body)
 		}
 
-		instance.Status.InstanceStatus = string(auraInstance.JSON200.Data.Status)
-
-		switch auraInstance.JSON200.Data.Status {
-		case auraclient.InstanceDataStatusRunning:
-			conditions.Delete(&instance, infrav1beta1.ConditionReconciling)
-			instance = infrav1beta1.AuraInstanceReady(instance, metav1.ConditionTrue, "InstanceRunning", "Instance is running")
-		case auraclient.InstanceDataStatusCreating:
-			instance = infrav1beta1.AuraInstanceReconciling(instance, metav1.ConditionTrue, "InstanceCreating", "Instance is being created")
-			return instance, reconcile.Result{RequeueAfter: time.Second * 30}, nil
-		default:
-			instance = infrav1beta1.AuraInstanceReady(instance, metav1.ConditionFalse, "InstanceNotReady", fmt.Sprintf("Instance status: %s", instance.Status.InstanceStatus))
+		setInstanceDetails(&instance, auraInstance.JSON200)
+		metrics.RecordInstance(instance, auraInstance.JSON200)
+
+		if err := r.reconcileConnectionSecret(ctx, instance, auraInstance.JSON200.Data.ConnectionUrl, logger); err != nil {
+			return instance, reconcile.Result{}, err
 		}
+		instance.Status.ConnectionSecret = connectionSecretName
+		setBinding(&instance)
 
-		if instance.Spec.Memory != string(auraInstance.JSON200.Data.Memory) ||
-			instance.Spec.GraphAnalyticsPlugin != *auraInstance.JSON200.Data.GraphAnalyticsPlugin ||
-			instance.Spec.VectorOptimized != *auraInstance.JSON200.Data.VectorOptimized {
-			logger.Info("updating aura instance")
-			instance = infrav1beta1.AuraInstanceReconciling(instance, metav1.ConditionTrue, "UpdatingInstance", "Updating Aura instance")
+		instance, err = r.reconcileReplicas(ctx, instance, logger)
+		if err != nil {
+			return instance, reconcile.Result{}, err
+		}
+
+		if err := r.reconcileSinks(ctx, instance); err != nil {
+			return instance, reconcile.Result{}, err
+		}
 
-			memory := auraclient.InstanceMemory(instance.Spec.Memory)
+		instance, err = r.reconcileOutputs(ctx, instance, auraInstance.JSON200.Data.ConnectionUrl)
+		if err != nil {
+			return instance, reconcile.Result{}, err
+		}
 
-			patchReq := auraclient.PatchInstanceIdJSONRequestBody{
-				GraphAnalyticsPlugin: &instance.Spec.GraphAnalyticsPlugin,
-				Memory:               &memory,
-				VectorOptimized:      &instance.Spec.VectorOptimized,
+		state := instanceLifecycleState(auraInstance.JSON200.Data.Status)
+		previousReason := conditions.GetReason(&instance, infrav1beta1.ConditionReady)
+		instance = setLifecycleConditions(instance, state)
+
+		switch {
+		case state.transient:
+			return instance, reconcile.Result{RequeueAfter: transientPollInterval}, nil
+		case state.terminal:
+			if previousReason != state.reason {
+				logger.Info("aura instance failed", "status", instance.Status.InstanceStatus)
+				r.Recorder.Event(&instance, "Warning", state.reason, state.message)
 			}
 
-			auraInstance, err := auraClient.PatchInstanceIdWithResponse(ctx, instance.Status.InstanceID, patchReq)
-			if err != nil {
-				return instance, reconcile.Result{}, fmt.Errorf("failed to update instance: %w", err)
+			return instance, reconcile.Result{}, nil
+		}
+
+		var rotateAfter, retryBootstrapAfter, recheckReachableAfter time.Duration
+		if auraInstance.JSON200.Data.Status == auraclient.InstanceDataStatusRunning {
+			// Aura only provides the metrics endpoint of running instances
+			instance = r.reconcileScrapeConfig(ctx, instance, auraClient, auraInstance.JSON200, logger)
+
+			// The password rotation and bootstrap scripts need a Bolt connection. Spec changes are still applied
+			// as more resources might be exactly what makes the database reachable again.
+			var reachable bool
+			instance, reachable = r.reconcileDatabaseReachable(ctx, instance)
+			if reachable {
+				instance, rotateAfter, err = r.reconcilePasswordRotation(ctx, instance, auraInstance.JSON200.Data.ConnectionUrl, logger)
+				if err != nil {
+					return instance, reconcile.Result{}, err
+				}
+
+				instance, retryBootstrapAfter = r.reconcileBootstrap(ctx, instance, logger)
+			} else {
+				recheckReachableAfter = databaseReachableRetryInterval
 			}
+		}
 
-			if auraInstance.StatusCode() != http.StatusAccepted {
-				return instance, reconcile.Result{}, fmt.Errorf("failed to update instance, request failed with code %d - %s", auraInstance.StatusCode(), auraInstance.Body)
+		var result ctrl.Result
+		instance, result, err = r.reconcileDrift(ctx, instance, auraClient, auraInstance.JSON200, logger)
+		for _, after := range []time.Duration{rotateAfter, retryBootstrapAfter, recheckReachableAfter} {
+			if err == nil && !result.Requeue && after > 0 && (result.RequeueAfter == 0 || after < result.RequeueAfter) {
+				result.RequeueAfter = after
 			}
 		}
 
-		return instance, reconcile.Result{}, nil
+		return instance, result, err
 	}
 
 	params := auraclient.GetInstancesParams{
@@ -319,22 +522,22 @@ func (r *AuraInstanceReconciler) reconcile(ctx context.Context, instance infrav1
 		}
 	}
 
-	logger.Info("creating new aura instance")
-	instance = infrav1beta1.AuraInstanceReconciling(instance, metav1.ConditionTrue, "CreatingInstance", "Creating new Aura instance")
+	p := planCreate(instance)
+	instance, dryRun := r.recordPlan(instance, p, logger)
+	if dryRun {
+		instance = infrav1beta1.AuraInstanceReady(instance, metav1.ConditionFalse, "DryRun", "Instance is not created in dry run mode")
+		return instance, reconcile.Result{}, nil
+	}
 
-	createReq := auraclient.PostInstancesJSONBody{
-		CloudProvider:        auraclient.CloudProvider(instance.Spec.CloudProvider),
-		Memory:               auraclient.InstanceMemory(instance.Spec.Memory),
-		Name:                 instance.Name,
-		Region:               auraclient.InstanceRegion(instance.Spec.Region),
-		TenantId:             instance.Spec.TenantID,
-		Type:                 auraclient.InstanceType(instance.Spec.Tier),
-		Version:              auraclient.InstanceVersion(instance.Spec.Neo4jVersion),
-		VectorOptimized:      &instance.Spec.VectorOptimized,
-		GraphAnalyticsPlugin: &instance.Spec.GraphAnalyticsPlugin,
+	if err := validateConnectionSecretTemplate(instance); err != nil {
+		instance = stall(instance, "InvalidConnectionSecretTemplate", err.Error())
+		return instance, reconcile.Result{}, nil
 	}
 
-	auraInstance, err := auraClient.PostInstancesWithResponse(ctx, auraclient.PostInstancesJSONRequestBody(createReq))
+	logger.Info("creating new aura instance")
+	instance = infrav1beta1.AuraInstanceReconciling(instance, metav1.ConditionTrue, "CreatingInstance", "Creating new Aura instance")
+
+	auraInstance, err := auraClient.PostInstancesWithResponse(ctx, p[0].create)
 	if err != nil {
 		return instance, reconcile.Result{}, fmt.Errorf("failed to create the instance: %w", err)
 	}
@@ -343,37 +546,347 @@ func (r *AuraInstanceReconciler) reconcile(ctx context.Context, instance infrav1
 		return instance, reconcile.Result{}, fmt.Errorf("failed to create the instance, request failed with code %d - %s", auraInstance.StatusCode(), auraInstance.Body)
 	}
 
+	setPlan(&instance, nil)
 	instance.Status.InstanceID = auraInstance.JSON202.Data.Id
 	instance.Status.ConnectionSecret = connectionSecretName
+	setBinding(&instance)
 
-	connectionDetails := corev1.Secret{
-		StringData: map[string]string{
-			"username":      auraInstance.JSON202.Data.Username,
-			"password":      auraInstance.JSON202.Data.Password,
-			"connectionURL": auraInstance.JSON202.Data.ConnectionUrl,
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      connectionSecretName,
-			Namespace: instance.Namespace,
-			OwnerReferences: []metav1.OwnerReference{
-				{
-					APIVersion: instance.APIVersion,
-					Kind:       instance.Kind,
-					Name:       instance.Name,
-					UID:        instance.UID,
-				},
-			},
-		},
+	// The credentials are backed up first as they can't be recovered if the connection secret fails to be created
+	backupErr := r.writeCredentialsBackup(ctx, instance, auraInstance.JSON202.Data.Username, auraInstance.JSON202.Data.Password, auraInstance.JSON202.Data.ConnectionUrl)
+	if backupErr != nil {
+		logger.Error(backupErr, "failed to back up credentials")
 	}
 
-	if err := r.Create(ctx, &connectionDetails); err != nil {
+	data, err := connectionSecretData(instance, auraInstance.JSON202.Data.Username, auraInstance.JSON202.Data.Password, auraInstance.JSON202.Data.ConnectionUrl)
+	if err != nil {
+		// The initial credentials are only returned once, they are stored using the default keys instead
+		logger.Error(err, "failed to render connection secret template")
+		r.Recorder.Event(&instance, "Warning", "InvalidConnectionSecretTemplate", fmt.Sprintf("Failed to render connection secret template, using default keys: %s", err))
+	}
+
+	if err := r.connectionSecretSink(&instance).Write(ctx, secretData(data)); err != nil {
 		return instance, reconcile.Result{}, fmt.Errorf("failed to create connection secret: %w", err)
 	}
 
+	if err := r.writeSinks(ctx, instance, secretData(data)); err != nil {
+		return instance, reconcile.Result{}, err
+	}
+
+	if backupErr != nil {
+		return instance, reconcile.Result{}, fmt.Errorf("failed to back up credentials: %w", backupErr)
+	}
+
 	r.Recorder.Event(&instance, "Normal", "InstanceCreated", fmt.Sprintf("Created aura instance %q", instance.Status.InstanceID))
 	return instance, reconcile.Result{RequeueAfter: time.Second * 30}, nil
 }
 
+// reconcileConnectionSecret restores the connection secret if it was deleted or modified.
+// Without a credentials backup only the keys which don't contain credentials can be restored.
+func (r *AuraInstanceReconciler) reconcileConnectionSecret(ctx context.Context, instance infrav1beta1.AuraInstance, connectionURL string, logger logr.Logger) error {
+	var backup corev1.Secret
+	err := r.Get(ctx, types.NamespacedName{
+		Name:      credentialsBackupName(instance),
+		Namespace: instance.Namespace,
+	}, &backup)
+
+	if err != nil && !kerrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get credentials backup: %w", err)
+	}
+
+	hasBackup := err == nil
+
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      connectionSecretName(instance),
+			Namespace: instance.Namespace,
+		},
+	}
+
+	err = r.Get(ctx, client.ObjectKeyFromObject(&secret), &secret)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get connection secret: %w", err)
+	}
+
+	exists := err == nil
+
+	// Credentials are never read from a secret which doesn't belong to the instance
+	if exists && !sink.IsOwnedBy(&secret, &instance) {
+		return fmt.Errorf("failed to reconcile connection secret %s: %w", secret.Name, sink.ErrNotControlled)
+	}
+
+	// Instances created before credentials were backed up still have them in the connection secret
+	if !hasBackup && exists && len(secret.Data[credentialsUsernameKey]) > 0 && len(secret.Data[credentialsPasswordKey]) > 0 {
+		if err := r.writeCredentialsBackup(ctx, instance, string(secret.Data[credentialsUsernameKey]), string(secret.Data[credentialsPasswordKey]), connectionURL); err != nil {
+			return fmt.Errorf("failed to back up credentials: %w", err)
+		}
+
+		return nil
+	}
+
+	if connectionURL == "" {
+		connectionURL = string(backup.Data[credentialsConnectionURLKey])
+	}
+
+	var desired map[string][]byte
+	if hasBackup {
+		data, err := renderConnectionSecretFromBackup(instance, backup, connectionURL)
+		if err != nil {
+			return err
+		}
+
+		desired = secretData(data)
+	} else {
+		restorable, err := credentialIndependentData(instance, connectionURL)
+		if err != nil {
+			return fmt.Errorf("failed to render connection secret: %w", err)
+		}
+
+		desired = repairConnectionSecretData(secret.Data, restorable)
+	}
+
+	if exists && equality.Semantic.DeepEqual(secret.Data, desired) && metav1.IsControlledBy(&secret, &instance) {
+		return nil
+	}
+
+	if err := r.connectionSecretSink(&instance).Write(ctx, desired); err != nil {
+		return fmt.Errorf("failed to write connection secret: %w", err)
+	}
+
+	msg := fmt.Sprintf("Restored connection secret %q", secret.Name)
+	if !hasBackup {
+		msg = fmt.Sprintf("Restored connection secret %q without credentials, no credentials backup found", secret.Name)
+		r.Recorder.Event(&instance, "Warning", "ConnectionSecretRestored", msg)
+	} else {
+		r.Recorder.Event(&instance, "Normal", "ConnectionSecretRestored", msg)
+	}
+
+	logger.Info(msg)
+	return nil
+}
+
+// writeCredentialsBackup creates or updates the credentials backup of the instance
+func (r *AuraInstanceReconciler) writeCredentialsBackup(ctx context.Context, instance infrav1beta1.AuraInstance, username, password, connectionURL string) error {
+	return sink.NewKubernetes(r.Client, &instance, credentialsBackupName(instance)).Write(ctx, secretData(map[string]string{
+		credentialsUsernameKey:      username,
+		credentialsPasswordKey:      password,
+		credentialsConnectionURLKey: connectionURL,
+	}))
+}
+
+// deleteSecret deletes a secret owned by the instance
+func (r *AuraInstanceReconciler) deleteSecret(ctx context.Context, instance infrav1beta1.AuraInstance, name string) error {
+	return sink.NewKubernetes(r.Client, &instance, name).Delete(ctx)
+}
+
+func (r *AuraInstanceReconciler) reconcileDrift(ctx context.Context, instance infrav1beta1.AuraInstance, auraClient *auraclient.ClientWithResponses, remote *auraclient.Instance, logger logr.Logger) (infrav1beta1.AuraInstance, ctrl.Result, error) {
+	// Fields can't be compared reliably while Aura is applying changes to the instance
+	if remote.Data.Status != auraclient.InstanceDataStatusRunning && remote.Data.Status != auraclient.InstanceDataStatusPaused {
+		return instance, reconcile.Result{RequeueAfter: time.Second * 30}, nil
+	}
+
+	drifts := detectDrift(instance, remote)
+	if len(drifts) == 0 {
+		setPlan(&instance, nil)
+		instance = infrav1beta1.AuraInstanceDrifted(instance, metav1.ConditionFalse, "NoDriftDetected", "Instance matches the desired state")
+		return observeGeneration(instance), reconcile.Result{}, nil
+	}
+
+	// Differences caused by a spec change are applied regardless of the drift policy,
+	// only changes made outside of the controller are considered drift.
+	if appliedGeneration(instance) != instance.Generation {
+		instance = infrav1beta1.AuraInstanceDrifted(instance, metav1.ConditionFalse, "SpecChanged", "Applying changes from the spec")
+	} else {
+		msg := fmt.Sprintf("Drift detected: %s", drifts)
+		if !conditions.IsTrue(&instance, infrav1beta1.ConditionDrifted) || conditions.GetMessage(&instance, infrav1beta1.ConditionDrifted) != msg {
+			logger.Info("aura instance drift detected", "drift", drifts.String())
+			r.Recorder.Event(&instance, "Warning", "DriftDetected", msg)
+		}
+
+		instance = infrav1beta1.AuraInstanceDrifted(instance, metav1.ConditionTrue, "DriftDetected", msg)
+		if instance.Spec.DriftPolicy == infrav1beta1.DriftPolicyReport {
+			setPlan(&instance, nil)
+			return instance, reconcile.Result{}, nil
+		}
+	}
+
+	previousSchedule := scheduledAt(instance.Status.PlannedChanges)
+	p := planDrift(instance, remote, drifts)
+	instance, dryRun := r.recordPlan(instance, p, logger)
+	if dryRun || len(p) == 0 {
+		// Planned changes keep the generation in progress, drift which can't be corrected does not
+		return observeGeneration(instance), reconcile.Result{}, nil
+	}
+
+	instance, approved := r.checkApproval(instance, p, logger)
+	if !approved {
+		return instance, reconcile.Result{}, nil
+	}
+
+	// Only the first operation is applied, the instance is not accepting further changes
+	// until Aura has finished applying it.
+	op := p[0]
+	if requiresMaintenanceWindow(op) && len(instance.Spec.MaintenanceWindows) > 0 {
+		now := time.Now()
+		open, next, err := nextMaintenanceWindow(instance.Spec.MaintenanceWindows, now)
+		if err != nil {
+			return instance, reconcile.Result{}, err
+		}
+
+		if !open {
+			scheduled := metav1.NewTime(next)
+			for i, op := range p {
+				if requiresMaintenanceWindow(op) {
+					instance.Status.PlannedChanges[i].ScheduledAt = &scheduled
+				}
+			}
+
+			if previousSchedule == nil || !previousSchedule.Equal(&scheduled) {
+				msg := fmt.Sprintf("Changes deferred to the maintenance window starting at %s: %s", next.UTC().Format(time.RFC3339), p)
+				logger.Info("deferring changes to the next maintenance window", "scheduledAt", next, "plan", p.String())
+				r.Recorder.Event(&instance, "Normal", "ChangesDeferred", msg)
+			}
+
+			return instance, reconcile.Result{RequeueAfter: next.Sub(now)}, nil
+		}
+	}
+
+	if err := r.applyOperation(ctx, auraClient, instance, op, logger); err != nil {
+		return instance, reconcile.Result{}, err
+	}
+
+	// An approval is consumed once the approved changes have been applied, the same changes need to be approved again
+	// if they are planned again. Until then it is carried over to the approved changes which have not been applied yet.
+	if instance.Spec.ChangePolicy == infrav1beta1.ChangePolicyRequireApproval && op.Disruptive {
+		var remaining *string
+		if hash := p[1:].disruptive().hash(); hash != "" {
+			remaining = &hash
+		}
+
+		logger.Info("approved changes applied", "hash", instance.GetAnnotations()[infrav1beta1.ApprovedChangesAnnotation])
+		if err := r.patchAnnotations(ctx, &instance, map[string]*string{infrav1beta1.ApprovedChangesAnnotation: remaining}); err != nil {
+			return instance, reconcile.Result{}, fmt.Errorf("failed to consume approval: %w", err)
+		}
+	}
+
+	setPlan(&instance, p[1:])
+	if state, ok := operationLifecycle[op.Operation]; ok {
+		instance = setLifecycleConditions(instance, state)
+	}
+
+	return instance, reconcile.Result{RequeueAfter: transientPollInterval}, nil
+}
+
+// checkApproval reports whether the next planned operation may be applied.
+// Disruptive operations are held in the PendingApproval condition until the approved-changes annotation
+// matches the hash of the planned disruptive changes if the instance requires approvals.
+func (r *AuraInstanceReconciler) checkApproval(instance infrav1beta1.AuraInstance, p plan, logger logr.Logger) (infrav1beta1.AuraInstance, bool) {
+	disruptive := p.disruptive()
+	if instance.Spec.ChangePolicy != infrav1beta1.ChangePolicyRequireApproval || len(disruptive) == 0 {
+		conditions.Delete(&instance, infrav1beta1.ConditionPendingApproval)
+		return instance, true
+	}
+
+	hash := disruptive.hash()
+	if instance.GetAnnotations()[infrav1beta1.ApprovedChangesAnnotation] == hash {
+		instance = infrav1beta1.AuraInstancePendingApproval(instance, metav1.ConditionFalse, "ChangesApproved", fmt.Sprintf("Changes %s have been approved", hash))
+		return instance, true
+	}
+
+	msg := fmt.Sprintf("Disruptive changes require approval using annotation %s=%s: %s", infrav1beta1.ApprovedChangesAnnotation, hash, disruptive)
+	if !conditions.IsTrue(&instance, infrav1beta1.ConditionPendingApproval) || conditions.GetMessage(&instance, infrav1beta1.ConditionPendingApproval) != msg {
+		logger.Info("disruptive changes pending approval", "hash", hash, "plan", disruptive.String())
+		r.Recorder.Event(&instance, "Normal", "PendingApproval", msg)
+	}
+
+	instance = infrav1beta1.AuraInstancePendingApproval(instance, metav1.ConditionTrue, "ApprovalRequired", msg)
+
+	// Non disruptive operations planned before the disruptive ones are still applied
+	return instance, !p[0].Disruptive
+}
+
+// patchAnnotations sets the annotations of the instance, annotations with a nil value are removed.
+// A copy is patched as the response would replace the status which has not been written yet.
+func (r *AuraInstanceReconciler) patchAnnotations(ctx context.Context, instance *infrav1beta1.AuraInstance, annotations map[string]*string) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	obj := instance.DeepCopy()
+	if err := r.Patch(ctx, obj, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return err
+	}
+
+	instance.SetAnnotations(obj.GetAnnotations())
+	return nil
+}
+
+// isDryRun returns true if mutating Aura API calls must be skipped for the instance
+func (r *AuraInstanceReconciler) isDryRun(instance infrav1beta1.AuraInstance) bool {
+	return r.DryRun || instance.GetAnnotations()[infrav1beta1.DryRunAnnotation] == "true"
+}
+
+// recordPlan records the planned changes in the status and reports whether they must not be applied
+// because the instance is in dry run mode.
+func (r *AuraInstanceReconciler) recordPlan(instance infrav1beta1.AuraInstance, p plan, logger logr.Logger) (infrav1beta1.AuraInstance, bool) {
+	changed := !equality.Semantic.DeepEqual(instance.Status.PlannedChanges, p.changes())
+	setPlan(&instance, p)
+
+	if !r.isDryRun(instance) {
+		return instance, false
+	}
+
+	if changed && len(p) > 0 {
+		logger.Info("dry run, skipping planned changes", "plan", p.String())
+		r.Recorder.Event(&instance, "Normal", "DryRun", fmt.Sprintf("Planned changes: %s", p))
+	}
+
+	return instance, true
+}
+
+// applyOperation executes a planned operation against the Aura API
+func (r *AuraInstanceReconciler) applyOperation(ctx context.Context, auraClient *auraclient.ClientWithResponses, instance infrav1beta1.AuraInstance, op operation, logger logr.Logger) error {
+	logger.Info("applying planned change", "change", op.String())
+
+	switch op.Operation {
+	case infrav1beta1.AuraOperationResume:
+		res, err := auraClient.PostResumeInstanceWithResponse(ctx, instance.Status.InstanceID, auraclient.PostResumeInstanceJSONRequestBody{})
+		if err != nil {
+			return fmt.Errorf("failed to resume instance: %w", err)
+		}
+
+		if res.StatusCode() != http.StatusAccepted {
+			return fmt.Errorf("failed to resume instance, request failed with code %d - %s", res.StatusCode(), res.Body)
+		}
+	case infrav1beta1.AuraOperationUpgrade:
+		res, err := auraClient.PostUpgradeInstanceWithResponse(ctx, instance.Status.InstanceID, op.upgrade)
+		if err != nil {
+			return fmt.Errorf("failed to upgrade instance: %w", err)
+		}
+
+		if res.StatusCode() != http.StatusOK && res.StatusCode() != http.StatusAccepted {
+			return fmt.Errorf("failed to upgrade instance, request failed with code %d - %s", res.StatusCode(), res.Body)
+		}
+	case infrav1beta1.AuraOperationPatch:
+		res, err := auraClient.PatchInstanceIdWithResponse(ctx, instance.Status.InstanceID, op.patch)
+		if err != nil {
+			return fmt.Errorf("failed to update instance: %w", err)
+		}
+
+		if res.StatusCode() != http.StatusOK && res.StatusCode() != http.StatusAccepted {
+			return fmt.Errorf("failed to update instance, request failed with code %d - %s", res.StatusCode(), res.Body)
+		}
+	default:
+		return fmt.Errorf("unsupported operation %s", op.Operation)
+	}
+
+	return nil
+}
+
 func (r *AuraInstanceReconciler) patchStatus(ctx context.Context, instance *infrav1beta1.AuraInstance) error {
 	key := client.ObjectKeyFromObject(instance)
 	latest := &infrav1beta1.AuraInstance{}
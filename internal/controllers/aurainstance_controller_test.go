@@ -3,9 +3,11 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	"github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/fake"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
@@ -20,6 +22,20 @@ var _ = Describe("AuraInstance controller", func() {
 		interval = time.Millisecond * 600
 	)
 
+	// Listing instances fails the same way the mocked Aura API of these tests always did
+	BeforeEach(func() {
+		auraServer.Fail(fake.Failure{
+			Method:     http.MethodGet,
+			Endpoint:   "/instances",
+			StatusCode: http.StatusInternalServerError,
+			Body:       `{"error":"error"}`,
+		})
+	})
+
+	AfterEach(func() {
+		auraServer.ClearFailures()
+	})
+
 	When("reconciling a suspended AuraInstance", func() {
 		instanceName := fmt.Sprintf("cluster-%s", rand.String(5))
 
@@ -94,6 +110,12 @@ var _ = Describe("AuraInstance controller", func() {
 						Reason:  "ReconciliationFailed",
 						Message: fmt.Sprintf(`failed to get secret: Secret "%s" not found`, secretName),
 					},
+					{
+						Type:    v1beta1.ConditionReconciling,
+						Status:  metav1.ConditionTrue,
+						Reason:  "ProgressingWithRetry",
+						Message: "Reconciliation failed and is retried",
+					},
 				},
 			}
 
@@ -102,7 +124,10 @@ var _ = Describe("AuraInstance controller", func() {
 				if err != nil {
 					return err
 				}
-				return needsExactConditions(expectedStatus.Conditions, reconciledInstance.Status.Conditions)
+				if err := needsExactConditions(expectedStatus.Conditions, reconciledInstance.Status.Conditions); err != nil {
+					return err
+				}
+				return needsObservedGeneration(expectedStatus.ObservedGeneration, reconciledInstance.Status.ObservedGeneration)
 			}, timeout, interval).Should(Not(HaveOccurred()))
 		})
 	})
@@ -133,6 +158,12 @@ var _ = Describe("AuraInstance controller", func() {
 						Reason:  "ReconciliationFailed",
 						Message: "secret must contain clientID and clientSecret keys",
 					},
+					{
+						Type:    v1beta1.ConditionReconciling,
+						Status:  metav1.ConditionTrue,
+						Reason:  "ProgressingWithRetry",
+						Message: "Reconciliation failed and is retried",
+					},
 				},
 			}
 
@@ -141,7 +172,10 @@ var _ = Describe("AuraInstance controller", func() {
 				if err != nil {
 					return err
 				}
-				return needsExactConditions(expectedStatus.Conditions, reconciledInstance.Status.Conditions)
+				if err := needsExactConditions(expectedStatus.Conditions, reconciledInstance.Status.Conditions); err != nil {
+					return err
+				}
+				return needsObservedGeneration(expectedStatus.ObservedGeneration, reconciledInstance.Status.ObservedGeneration)
 			}, timeout, interval).Should(Not(HaveOccurred()))
 		})
 	})
@@ -170,6 +204,12 @@ var _ = Describe("AuraInstance controller", func() {
 						Reason:  "ReconciliationFailed",
 						Message: "secret must contain clientID and clientSecret keys",
 					},
+					{
+						Type:    v1beta1.ConditionReconciling,
+						Status:  metav1.ConditionTrue,
+						Reason:  "ProgressingWithRetry",
+						Message: "Reconciliation failed and is retried",
+					},
 				},
 			}
 
@@ -178,7 +218,10 @@ var _ = Describe("AuraInstance controller", func() {
 				if err != nil {
 					return err
 				}
-				return needsExactConditions(expectedStatus.Conditions, reconciledInstance.Status.Conditions)
+				if err := needsExactConditions(expectedStatus.Conditions, reconciledInstance.Status.Conditions); err != nil {
+					return err
+				}
+				return needsObservedGeneration(expectedStatus.ObservedGeneration, reconciledInstance.Status.ObservedGeneration)
 			}, timeout, interval).Should(Not(HaveOccurred()))
 		})
 	})
@@ -237,6 +280,12 @@ var _ = Describe("AuraInstance controller", func() {
 						Reason:  "ReconciliationFailed",
 						Message: `failed to get instance list, request failed with code 500 - {"error":"error"}`,
 					},
+					{
+						Type:    v1beta1.ConditionReconciling,
+						Status:  metav1.ConditionTrue,
+						Reason:  "ProgressingWithRetry",
+						Message: "Reconciliation failed and is retried",
+					},
 				},
 			}
 
@@ -245,7 +294,10 @@ var _ = Describe("AuraInstance controller", func() {
 				if err != nil {
 					return err
 				}
-				return needsExactConditions(expectedStatus.Conditions, reconciledInstance.Status.Conditions)
+				if err := needsExactConditions(expectedStatus.Conditions, reconciledInstance.Status.Conditions); err != nil {
+					return err
+				}
+				return needsObservedGeneration(expectedStatus.ObservedGeneration, reconciledInstance.Status.ObservedGeneration)
 			}, timeout, interval).Should(Not(HaveOccurred()))
 		})
 	})
@@ -303,6 +355,12 @@ var _ = Describe("AuraInstance controller", func() {
 						Reason:  "ReconciliationFailed",
 						Message: `failed to get instance list, request failed with code 500 - {"error":"error"}`,
 					},
+					{
+						Type:    v1beta1.ConditionReconciling,
+						Status:  metav1.ConditionTrue,
+						Reason:  "ProgressingWithRetry",
+						Message: "Reconciliation failed and is retried",
+					},
 				},
 			}
 
@@ -311,7 +369,10 @@ var _ = Describe("AuraInstance controller", func() {
 				if err != nil {
 					return err
 				}
-				return needsExactConditions(expectedStatus.Conditions, reconciledInstance.Status.Conditions)
+				if err := needsExactConditions(expectedStatus.Conditions, reconciledInstance.Status.Conditions); err != nil {
+					return err
+				}
+				return needsObservedGeneration(expectedStatus.ObservedGeneration, reconciledInstance.Status.ObservedGeneration)
 			}, timeout, interval).Should(Not(HaveOccurred()))
 		})
 	})
@@ -369,6 +430,12 @@ var _ = Describe("AuraInstance controller", func() {
 						Reason:  "ReconciliationFailed",
 						Message: `failed to get instance list, request failed with code 500 - {"error":"error"}`,
 					},
+					{
+						Type:    v1beta1.ConditionReconciling,
+						Status:  metav1.ConditionTrue,
+						Reason:  "ProgressingWithRetry",
+						Message: "Reconciliation failed and is retried",
+					},
 				},
 			}
 
@@ -377,7 +444,10 @@ var _ = Describe("AuraInstance controller", func() {
 				if err != nil {
 					return err
 				}
-				return needsExactConditions(expectedStatus.Conditions, reconciledInstance.Status.Conditions)
+				if err := needsExactConditions(expectedStatus.Conditions, reconciledInstance.Status.Conditions); err != nil {
+					return err
+				}
+				return needsObservedGeneration(expectedStatus.ObservedGeneration, reconciledInstance.Status.ObservedGeneration)
 			}, timeout, interval).Should(Not(HaveOccurred()))
 		})
 	})
@@ -435,6 +505,12 @@ var _ = Describe("AuraInstance controller", func() {
 						Reason:  "ReconciliationFailed",
 						Message: "secret must contain wrongClientId and clientSecret keys",
 					},
+					{
+						Type:    v1beta1.ConditionReconciling,
+						Status:  metav1.ConditionTrue,
+						Reason:  "ProgressingWithRetry",
+						Message: "Reconciliation failed and is retried",
+					},
 				},
 			}
 
@@ -443,7 +519,10 @@ var _ = Describe("AuraInstance controller", func() {
 				if err != nil {
 					return err
 				}
-				return needsExactConditions(expectedStatus.Conditions, reconciledInstance.Status.Conditions)
+				if err := needsExactConditions(expectedStatus.Conditions, reconciledInstance.Status.Conditions); err != nil {
+					return err
+				}
+				return needsObservedGeneration(expectedStatus.ObservedGeneration, reconciledInstance.Status.ObservedGeneration)
 			}, timeout, interval).Should(Not(HaveOccurred()))
 		})
 	})
@@ -472,6 +551,12 @@ var _ = Describe("AuraInstance controller", func() {
 						Reason:  "ReconciliationFailed",
 						Message: `failed to get instance list, request failed with code 500 - {"error":"error"}`,
 					},
+					{
+						Type:    v1beta1.ConditionReconciling,
+						Status:  metav1.ConditionTrue,
+						Reason:  "ProgressingWithRetry",
+						Message: "Reconciliation failed and is retried",
+					},
 				},
 			}
 
@@ -480,7 +565,10 @@ var _ = Describe("AuraInstance controller", func() {
 				if err != nil {
 					return err
 				}
-				return needsExactConditions(expectedStatus.Conditions, reconciledInstance.Status.Conditions)
+				if err := needsExactConditions(expectedStatus.Conditions, reconciledInstance.Status.Conditions); err != nil {
+					return err
+				}
+				return needsObservedGeneration(expectedStatus.ObservedGeneration, reconciledInstance.Status.ObservedGeneration)
 			}, timeout, interval).Should(Not(HaveOccurred()))
 		})
 	})
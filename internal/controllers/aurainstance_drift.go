@@ -0,0 +1,112 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	infrav1beta1 "github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
+)
+
+const (
+	driftFieldName                 = "name"
+	driftFieldTier                 = "tier"
+	driftFieldRegion               = "region"
+	driftFieldCloudProvider        = "cloudProvider"
+	driftFieldMemory               = "memory"
+	driftFieldVectorOptimized      = "vectorOptimized"
+	driftFieldGraphAnalyticsPlugin = "graphAnalyticsPlugin"
+	driftFieldStatus               = "status"
+)
+
+// drift describes a single field which differs between the AuraInstance spec and the Aura instance
+type drift struct {
+	Field   string
+	Desired string
+	Actual  string
+}
+
+func (d drift) String() string {
+	return fmt.Sprintf("%s (desired: %s, actual: %s)", d.Field, d.Desired, d.Actual)
+}
+
+type driftList []drift
+
+func (l driftList) has(field string) bool {
+	for _, d := range l {
+		if d.Field == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l driftList) String() string {
+	fields := make([]string, 0, len(l))
+	for _, d := range l {
+		fields = append(fields, d.String())
+	}
+
+	return strings.Join(fields, ", ")
+}
+
+// detectDrift compares all fields managed by the controller against the Aura instance
+func detectDrift(instance infrav1beta1.AuraInstance, remote *auraclient.Instance) driftList {
+	var drifts driftList
+	compare := func(field, desired, actual string) {
+		if desired != actual {
+			drifts = append(drifts, drift{Field: field, Desired: desired, Actual: actual})
+		}
+	}
+
+	compare(driftFieldName, instance.Name, remote.Data.Name)
+	compare(driftFieldTier, string(instance.Spec.Tier), string(remote.Data.Type))
+	compare(driftFieldRegion, instance.Spec.Region, remote.Data.Region)
+	compare(driftFieldCloudProvider, string(instance.Spec.CloudProvider), string(remote.Data.CloudProvider))
+
+	if instance.Spec.Memory != "" {
+		compare(driftFieldMemory, instance.Spec.Memory, remote.Data.Memory)
+	}
+
+	compare(driftFieldVectorOptimized, strconv.FormatBool(instance.Spec.VectorOptimized), strconv.FormatBool(boolValue(remote.Data.VectorOptimized)))
+	compare(driftFieldGraphAnalyticsPlugin, strconv.FormatBool(instance.Spec.GraphAnalyticsPlugin), strconv.FormatBool(boolValue(remote.Data.GraphAnalyticsPlugin)))
+
+	if remote.Data.Status == auraclient.InstanceDataStatusPaused {
+		compare(driftFieldStatus, string(auraclient.InstanceDataStatusRunning), string(remote.Data.Status))
+	}
+
+	return drifts
+}
+
+// isTierUpgrade returns true if the tier drift can be corrected using the upgrade endpoint.
+// Aura only supports upgrading free instances to professional instances.
+func isTierUpgrade(instance infrav1beta1.AuraInstance, remote *auraclient.Instance) bool {
+	return remote.Data.Type == auraclient.InstanceTypeFreeDb &&
+		instance.Spec.Tier == infrav1beta1.AuraInstanceTierProfessionalDb
+}
+
+func boolValue(b *bool) bool {
+	if b == nil {
+		return false
+	}
+
+	return *b
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("AuraInstance drift detection", func() {
+	newInstance := func() v1beta1.AuraInstance {
+		return v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "instance",
+			},
+			Spec: v1beta1.AuraInstanceSpec{
+				Tier:          v1beta1.AuraInstanceTierProfessionalDb,
+				Region:        "europe-west1",
+				CloudProvider: v1beta1.CloudProviderGCP,
+				Memory:        "8GB",
+			},
+		}
+	}
+
+	newRemote := func() *auraclient.Instance {
+		remote := &auraclient.Instance{}
+		remote.Data.Name = "instance"
+		remote.Data.Type = auraclient.InstanceTypeProfessionalDb
+		remote.Data.Region = "europe-west1"
+		remote.Data.CloudProvider = auraclient.CloudProviderGcp
+		remote.Data.Memory = "8GB"
+		remote.Data.Status = auraclient.InstanceDataStatusRunning
+		return remote
+	}
+
+	It("reports no drift if the instance matches the spec", func() {
+		Expect(detectDrift(newInstance(), newRemote())).To(BeEmpty())
+	})
+
+	It("ignores the memory if not set in the spec", func() {
+		instance := newInstance()
+		instance.Spec.Memory = ""
+		Expect(detectDrift(instance, newRemote())).To(BeEmpty())
+	})
+
+	It("detects drift for every managed field", func() {
+		remote := newRemote()
+		enabled := true
+		remote.Data.Name = "renamed"
+		remote.Data.Type = auraclient.InstanceTypeFreeDb
+		remote.Data.Region = "us-central1"
+		remote.Data.CloudProvider = auraclient.CloudProviderAws
+		remote.Data.Memory = "4GB"
+		remote.Data.VectorOptimized = &enabled
+		remote.Data.GraphAnalyticsPlugin = &enabled
+		remote.Data.Status = auraclient.InstanceDataStatusPaused
+
+		drifts := detectDrift(newInstance(), remote)
+		Expect(drifts).To(ConsistOf(
+			drift{Field: driftFieldName, Desired: "instance", Actual: "renamed"},
+			drift{Field: driftFieldTier, Desired: "professional-db", Actual: "free-db"},
+			drift{Field: driftFieldRegion, Desired: "europe-west1", Actual: "us-central1"},
+			drift{Field: driftFieldCloudProvider, Desired: "gcp", Actual: "aws"},
+			drift{Field: driftFieldMemory, Desired: "8GB", Actual: "4GB"},
+			drift{Field: driftFieldVectorOptimized, Desired: "false", Actual: "true"},
+			drift{Field: driftFieldGraphAnalyticsPlugin, Desired: "false", Actual: "true"},
+			drift{Field: driftFieldStatus, Desired: "running", Actual: "paused"},
+		))
+		Expect(drifts.has(driftFieldMemory)).To(BeTrue())
+		Expect(drifts.String()).To(ContainSubstring("memory (desired: 8GB, actual: 4GB)"))
+	})
+
+	It("only supports tier upgrades from free to professional", func() {
+		remote := newRemote()
+		remote.Data.Type = auraclient.InstanceTypeFreeDb
+		Expect(isTierUpgrade(newInstance(), remote)).To(BeTrue())
+
+		instance := newInstance()
+		instance.Spec.Tier = v1beta1.AuraInstanceTierBusinessCritical
+		Expect(isTierUpgrade(instance, remote)).To(BeFalse())
+	})
+})
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/sink"
+	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
+	"github.com/fluxcd/cli-utils/pkg/kstatus/status"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+// computeStatus computes the status of the instance the way Flux health checks do
+func computeStatus(instance v1beta1.AuraInstance) status.Status {
+	instance.TypeMeta = metav1.TypeMeta{
+		APIVersion: v1beta1.GroupVersion.String(),
+		Kind:       "AuraInstance",
+	}
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&instance)
+	Expect(err).NotTo(HaveOccurred())
+
+	res, err := status.Compute(&unstructured.Unstructured{Object: obj})
+	Expect(err).NotTo(HaveOccurred())
+	return res.Status
+}
+
+var _ = Describe("AuraInstance kstatus", func() {
+	newInstance := func() v1beta1.AuraInstance {
+		return v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "instance",
+				Namespace:  "default",
+				Generation: 2,
+			},
+			Status: v1beta1.AuraInstanceStatus{
+				ObservedGeneration: 1,
+			},
+		}
+	}
+
+	// reconciled mimics a reconciliation without drift, the drift is only compared once Aura has finished changing the instance
+	reconciled := func(instance v1beta1.AuraInstance, aura auraclient.InstanceDataStatus, err error) v1beta1.AuraInstance {
+		if err == nil {
+			state := instanceLifecycleState(aura)
+			instance = setLifecycleConditions(instance, state)
+			if !state.transient && !state.terminal {
+				instance = observeGeneration(instance)
+			}
+		}
+
+		return setReconcileResult(instance, err)
+	}
+
+	It("is in progress until the generation has been observed", func() {
+		Expect(computeStatus(newInstance())).To(Equal(status.InProgressStatus))
+	})
+
+	It("is current once the instance is running", func() {
+		instance := reconciled(newInstance(), auraclient.InstanceDataStatusRunning, nil)
+		Expect(instance.Status.ObservedGeneration).To(Equal(int64(2)))
+		Expect(computeStatus(instance)).To(Equal(status.CurrentStatus))
+	})
+
+	It("is in progress while Aura is changing the instance", func() {
+		for _, aura := range []auraclient.InstanceDataStatus{
+			auraclient.InstanceDataStatusCreating,
+			auraclient.InstanceDataStatusUpdating,
+			auraclient.InstanceDataStatusRestoring,
+			auraclient.InstanceDataStatusResuming,
+		} {
+			instance := reconciled(newInstance(), aura, nil)
+			Expect(instance.Status.ObservedGeneration).To(Equal(int64(1)))
+			Expect(computeStatus(instance)).To(Equal(status.InProgressStatus), string(aura))
+		}
+	})
+
+	It("is in progress if the instance is not ready", func() {
+		instance := reconciled(newInstance(), auraclient.InstanceDataStatusPaused, nil)
+		Expect(computeStatus(instance)).To(Equal(status.InProgressStatus))
+	})
+
+	It("is in progress while planned changes are not applied", func() {
+		instance := newInstance()
+		instance.Status.PlannedChanges = []v1beta1.PlannedChange{{Operation: v1beta1.AuraOperationPatch}}
+		instance = reconciled(instance, auraclient.InstanceDataStatusRunning, nil)
+
+		Expect(instance.Status.ObservedGeneration).To(Equal(int64(1)))
+		Expect(computeStatus(instance)).To(Equal(status.InProgressStatus))
+	})
+
+	It("observes the generation of a failed reconciliation without applying it", func() {
+		instance := reconciled(newInstance(), "", errors.New("aura unavailable"))
+		Expect(instance.Status.ObservedGeneration).To(Equal(int64(2)))
+		Expect(appliedGeneration(instance)).To(Equal(int64(1)))
+		Expect(computeStatus(instance)).To(Equal(status.InProgressStatus))
+	})
+
+	It("is in progress if a reconciliation of an observed generation fails", func() {
+		instance := reconciled(newInstance(), auraclient.InstanceDataStatusRunning, nil)
+		instance = reconciled(instance, "", errors.New("aura unavailable"))
+
+		Expect(instance.Status.ObservedGeneration).To(Equal(int64(2)))
+		Expect(computeStatus(instance)).To(Equal(status.InProgressStatus))
+	})
+
+	It("is failed if the instance is in a terminal state", func() {
+		for _, aura := range []auraclient.InstanceDataStatus{
+			auraclient.InstanceDataStatusLoadingFailed,
+			auraclient.InstanceDataStatusSuspended,
+		} {
+			instance := reconciled(newInstance(), aura, nil)
+			Expect(instance.Status.ObservedGeneration).To(Equal(int64(2)))
+			Expect(computeStatus(instance)).To(Equal(status.FailedStatus), string(aura))
+		}
+	})
+
+	It("is failed if the spec is invalid", func() {
+		instance := stall(newInstance(), "InvalidConnectionSecretTemplate", "invalid template")
+		instance = setReconcileResult(instance, nil)
+		Expect(computeStatus(instance)).To(Equal(status.FailedStatus))
+	})
+
+	It("is failed if a secret of the instance belongs to someone else", func() {
+		instance := reconciled(newInstance(), "", fmt.Errorf("failed to write connection secret: %w", sink.ErrNotControlled))
+
+		Expect(conditions.GetReason(&instance, v1beta1.ConditionStalled)).To(Equal("SecretConflict"))
+		Expect(conditions.Has(&instance, v1beta1.ConditionReconciling)).To(BeFalse())
+		Expect(computeStatus(instance)).To(Equal(status.FailedStatus))
+	})
+
+	It("recovers from a terminal state", func() {
+		instance := reconciled(newInstance(), auraclient.InstanceDataStatusLoadingFailed, nil)
+		instance = reconciled(instance, auraclient.InstanceDataStatusRestoring, nil)
+		Expect(computeStatus(instance)).To(Equal(status.InProgressStatus))
+
+		instance = reconciled(instance, auraclient.InstanceDataStatusRunning, nil)
+		Expect(computeStatus(instance)).To(Equal(status.CurrentStatus))
+	})
+
+	It("is in progress after a failed reconciliation by the controller", func() {
+		ctx := context.Background()
+		name := fmt.Sprintf("kstatus-%s", rand.String(5))
+
+		instance := &v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1beta1.AuraInstanceSpec{
+				TenantID:      "x",
+				Neo4jVersion:  "5",
+				Tier:          "free-db",
+				CloudProvider: "gcp",
+				Secret: v1beta1.SecretReference{
+					Name: "does-not-exist",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, instance)).Should(Succeed())
+
+		Eventually(func() string {
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, instance)).To(Succeed())
+			return string(computeStatus(*instance))
+		}, 4*time.Second, 200*time.Millisecond).Should(Equal(string(status.InProgressStatus)))
+
+		Expect(instance.Status.Conditions).NotTo(BeEmpty())
+		Expect(instance.Status.ObservedGeneration).To(Equal(instance.Generation))
+	})
+})
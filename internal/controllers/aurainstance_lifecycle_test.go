@@ -0,0 +1,286 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
+	"github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/fake"
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("AuraInstance lifecycle", Ordered, func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 200
+	)
+
+	ctx := context.Background()
+	tenantID := fmt.Sprintf("tenant-%s", rand.String(5))
+	instanceName := fmt.Sprintf("lifecycle-%s", rand.String(5))
+	instanceLookupKey := types.NamespacedName{Name: instanceName, Namespace: "default"}
+	instance := &v1beta1.AuraInstance{}
+
+	// Transient states are only polled every 30s, reconciliations are requested to observe transitions in time
+	requestReconcile := func() {
+		var latest v1beta1.AuraInstance
+		Expect(k8sClient.Get(ctx, instanceLookupKey, &latest)).To(Succeed())
+
+		patch := client.MergeFrom(latest.DeepCopy())
+		annotations := latest.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+
+		annotations[meta.ReconcileRequestAnnotation] = time.Now().Format(time.RFC3339Nano)
+		latest.SetAnnotations(annotations)
+		Expect(k8sClient.Patch(ctx, &latest, patch)).To(Succeed())
+	}
+
+	readyReason := func() string {
+		requestReconcile()
+		Expect(k8sClient.Get(ctx, instanceLookupKey, instance)).To(Succeed())
+		return conditions.GetReason(instance, v1beta1.ConditionReady)
+	}
+
+	remote := func() auraclient.Instance {
+		r, ok := auraServer.Instance(instance.Status.InstanceID)
+		Expect(ok).To(BeTrue())
+		return r
+	}
+
+	BeforeAll(func() {
+		auraServer.AddProject(tenantID, "lifecycle")
+
+		secretName := fmt.Sprintf("lifecycle-%s", rand.String(5))
+		Expect(k8sClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secretName,
+				Namespace: "default",
+			},
+			StringData: map[string]string{
+				"clientID":     "id",
+				"clientSecret": "secret",
+			},
+		})).To(Succeed())
+
+		Expect(k8sClient.Create(ctx, &v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      instanceName,
+				Namespace: "default",
+			},
+			Spec: v1beta1.AuraInstanceSpec{
+				TenantID:      tenantID,
+				Neo4jVersion:  "5",
+				Tier:          v1beta1.AuraInstanceTierProfessionalDb,
+				Memory:        "4GB",
+				Region:        "europe-west1",
+				CloudProvider: v1beta1.CloudProviderGCP,
+				Secret: v1beta1.SecretReference{
+					Name: secretName,
+				},
+			},
+		})).To(Succeed())
+	})
+
+	It("creates the instance in Aura", func() {
+		Eventually(func() string {
+			Expect(k8sClient.Get(ctx, instanceLookupKey, instance)).To(Succeed())
+			return instance.Status.InstanceID
+		}, timeout, interval).ShouldNot(BeEmpty())
+
+		Expect(auraServer.Requests(http.MethodPost, "/instances")).To(BeNumerically(">=", 1))
+		Expect(remote().Data.TenantId).To(Equal(tenantID))
+		Expect(remote().Data.Memory).To(Equal("4GB"))
+
+		By("storing the initial credentials in the connection secret")
+		username, password, ok := auraServer.Credentials(instance.Status.InstanceID)
+		Expect(ok).To(BeTrue())
+
+		var secret corev1.Secret
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: instance.Status.ConnectionSecret, Namespace: "default"}, &secret)).To(Succeed())
+		Expect(string(secret.Data["username"])).To(Equal(username))
+		Expect(string(secret.Data["password"])).To(Equal(password))
+	})
+
+	It("reports the instance as reconciling while it is being created", func() {
+		// Holds the instance in the creating state until the transition is completed manually
+		Expect(auraServer.SetInstanceStatus(instance.Status.InstanceID, auraclient.InstanceDataStatusCreating)).To(Succeed())
+
+		Eventually(readyReason, timeout, interval).Should(Equal("InstanceCreating"))
+		Expect(conditions.IsTrue(instance, v1beta1.ConditionReconciling)).To(BeTrue())
+	})
+
+	It("becomes ready once the instance is running", func() {
+		Expect(auraServer.SetInstanceStatus(instance.Status.InstanceID, auraclient.InstanceDataStatusRunning)).To(Succeed())
+
+		Eventually(readyReason, timeout, interval).Should(Equal("InstanceRunning"))
+		Expect(conditions.IsTrue(instance, v1beta1.ConditionReady)).To(BeTrue())
+		Expect(conditions.Has(instance, v1beta1.ConditionReconciling)).To(BeFalse())
+		Expect(instance.Status.InstanceStatus).To(Equal("running"))
+		Expect(instance.Status.Memory).To(Equal("4GB"))
+		Expect(instance.Status.Storage).To(Equal("8GB"))
+		Expect(instance.Status.ObservedGeneration).To(Equal(instance.Generation))
+	})
+
+	It("applies a spec change and waits for the update to finish", func() {
+		patch := client.MergeFrom(instance.DeepCopy())
+		instance.Spec.Memory = "8GB"
+		Expect(k8sClient.Patch(ctx, instance, patch)).To(Succeed())
+
+		Eventually(func() string {
+			Expect(k8sClient.Get(ctx, instanceLookupKey, instance)).To(Succeed())
+			return conditions.GetReason(instance, v1beta1.ConditionReady)
+		}, timeout, interval).Should(Equal("UpdatingInstance"))
+		Expect(conditions.IsTrue(instance, v1beta1.ConditionReconciling)).To(BeTrue())
+		Expect(remote().Data.Memory).To(Equal("8GB"))
+
+		Eventually(func() bool {
+			return readyReason() == "InstanceRunning" && instance.Status.Memory == "8GB"
+		}, timeout, interval).Should(BeTrue())
+		Expect(instance.Status.ObservedGeneration).To(Equal(instance.Generation))
+	})
+
+	It("resumes an instance paused outside of the controller", func() {
+		resumes := auraServer.Requests(http.MethodPost, "/instances/{instanceId}/resume")
+		Expect(auraServer.SetInstanceStatus(instance.Status.InstanceID, auraclient.InstanceDataStatusPaused)).To(Succeed())
+
+		Eventually(func() int {
+			requestReconcile()
+			return auraServer.Requests(http.MethodPost, "/instances/{instanceId}/resume")
+		}, timeout, interval).Should(BeNumerically(">", resumes))
+
+		Eventually(readyReason, timeout, interval).Should(Equal("InstanceRunning"))
+		Expect(remote().Data.Status).To(Equal(auraclient.InstanceDataStatusRunning))
+	})
+
+	It("stalls while the instance is suspended by Aura", func() {
+		Expect(auraServer.SetInstanceStatus(instance.Status.InstanceID, auraclient.InstanceDataStatusSuspended)).To(Succeed())
+
+		Eventually(readyReason, timeout, interval).Should(Equal("InstanceSuspended"))
+		Expect(conditions.IsTrue(instance, v1beta1.ConditionStalled)).To(BeTrue())
+
+		Expect(auraServer.SetInstanceStatus(instance.Status.InstanceID, auraclient.InstanceDataStatusRunning)).To(Succeed())
+		Eventually(readyReason, timeout, interval).Should(Equal("InstanceRunning"))
+		Expect(conditions.Has(instance, v1beta1.ConditionStalled)).To(BeFalse())
+	})
+
+	It("retries failed Aura API requests", func() {
+		auraServer.Fail(fake.Failure{
+			Method:     http.MethodGet,
+			Endpoint:   "/instances/{instanceId}",
+			StatusCode: http.StatusServiceUnavailable,
+		})
+
+		Eventually(readyReason, timeout, interval).Should(Equal("ReconciliationFailed"))
+		Expect(conditions.GetMessage(instance, v1beta1.ConditionReady)).To(Equal(`failed to get instance, request failed with code 503 - {"errors":[{"message":"injected failure for GET /instances/{instanceId}","reason":"injected-failure"}]}`))
+		Expect(conditions.GetReason(instance, v1beta1.ConditionReconciling)).To(Equal("ProgressingWithRetry"))
+
+		auraServer.ClearFailures()
+		Eventually(readyReason, timeout, interval).Should(Equal("InstanceRunning"))
+	})
+
+	It("applies a spec change made while Aura is updating the instance", func() {
+		// Holds the instance in the updating state until the transition is completed manually
+		Expect(auraServer.SetInstanceStatus(instance.Status.InstanceID, auraclient.InstanceDataStatusUpdating)).To(Succeed())
+		Eventually(readyReason, timeout, interval).Should(Equal("InstanceUpdating"))
+
+		// Differences are only reported with this policy unless they are caused by a spec change
+		patch := client.MergeFrom(instance.DeepCopy())
+		instance.Spec.Memory = "16GB"
+		instance.Spec.DriftPolicy = v1beta1.DriftPolicyReport
+		Expect(k8sClient.Patch(ctx, instance, patch)).To(Succeed())
+
+		Consistently(func() bool {
+			requestReconcile()
+			Expect(k8sClient.Get(ctx, instanceLookupKey, instance)).To(Succeed())
+			return instance.Status.ObservedGeneration == instance.Generation
+		}, time.Second*2, interval).Should(BeFalse())
+
+		Expect(auraServer.SetInstanceStatus(instance.Status.InstanceID, auraclient.InstanceDataStatusRunning)).To(Succeed())
+		Eventually(func() string {
+			requestReconcile()
+			return remote().Data.Memory
+		}, timeout, interval).Should(Equal("16GB"))
+
+		Eventually(func() bool {
+			return readyReason() == "InstanceRunning" && instance.Status.ObservedGeneration == instance.Generation
+		}, timeout, interval).Should(BeTrue())
+		Expect(conditions.IsTrue(instance, v1beta1.ConditionDrifted)).To(BeFalse())
+	})
+
+	It("requires a new approval if approved changes are planned again", func() {
+		pendingApproval := func() bool {
+			requestReconcile()
+			Expect(k8sClient.Get(ctx, instanceLookupKey, instance)).To(Succeed())
+			return conditions.IsTrue(instance, v1beta1.ConditionPendingApproval)
+		}
+
+		patch := client.MergeFrom(instance.DeepCopy())
+		instance.Spec.Memory = "8GB"
+		instance.Spec.DriftPolicy = v1beta1.DriftPolicyCorrect
+		instance.Spec.ChangePolicy = v1beta1.ChangePolicyRequireApproval
+		Expect(k8sClient.Patch(ctx, instance, patch)).To(Succeed())
+
+		Eventually(pendingApproval, timeout, interval).Should(BeTrue())
+		hash := instance.Status.PlannedChangesHash
+		Expect(hash).NotTo(BeEmpty())
+		Expect(remote().Data.Memory).To(Equal("16GB"))
+
+		By("consuming the approval once the changes are applied")
+		patch = client.MergeFrom(instance.DeepCopy())
+		instance.SetAnnotations(map[string]string{v1beta1.ApprovedChangesAnnotation: hash})
+		Expect(k8sClient.Patch(ctx, instance, patch)).To(Succeed())
+
+		Eventually(func() string {
+			requestReconcile()
+			return remote().Data.Memory
+		}, timeout, interval).Should(Equal("8GB"))
+
+		Expect(k8sClient.Get(ctx, instanceLookupKey, instance)).To(Succeed())
+		Expect(instance.GetAnnotations()).NotTo(HaveKey(v1beta1.ApprovedChangesAnnotation))
+		Eventually(readyReason, timeout, interval).Should(Equal("InstanceRunning"))
+
+		By("holding the same changes once they are planned again")
+		Expect(auraServer.UpdateInstance(instance.Status.InstanceID, func(i *auraclient.Instance) {
+			i.Data.Memory = "16GB"
+		})).To(Succeed())
+
+		Eventually(pendingApproval, timeout, interval).Should(BeTrue())
+		Expect(instance.Status.PlannedChangesHash).To(Equal(hash))
+		Consistently(func() string {
+			requestReconcile()
+			return remote().Data.Memory
+		}, time.Second*2, interval).Should(Equal("16GB"))
+	})
+
+	It("applies spec changes while the database is unreachable", func() {
+		patch := client.MergeFrom(instance.DeepCopy())
+		instance.Spec.Memory = "32GB"
+		instance.Spec.ChangePolicy = v1beta1.ChangePolicyAutomatic
+		instance.Spec.ReadinessCheck = &v1beta1.ReadinessCheck{Enabled: true}
+		Expect(k8sClient.Patch(ctx, instance, patch)).To(Succeed())
+
+		Eventually(func() string {
+			requestReconcile()
+			return remote().Data.Memory
+		}, timeout, interval).Should(Equal("32GB"))
+
+		Eventually(func() bool {
+			return readyReason() == "DatabaseUnreachable" && instance.Status.ObservedGeneration == instance.Generation
+		}, timeout, interval).Should(BeTrue())
+		Expect(conditions.IsFalse(instance, v1beta1.ConditionDatabaseReachable)).To(BeTrue())
+		Expect(instance.Status.Memory).To(Equal("32GB"))
+	})
+})
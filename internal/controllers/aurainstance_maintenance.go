@@ -0,0 +1,95 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	infrav1beta1 "github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// requiresMaintenanceWindow returns true if the operation may only be applied within a maintenance window
+func requiresMaintenanceWindow(op operation) bool {
+	switch op.Operation {
+	case infrav1beta1.AuraOperationPatch, infrav1beta1.AuraOperationUpgrade:
+		return true
+	default:
+		return false
+	}
+}
+
+// nextMaintenanceWindow returns whether one of the windows is open at the given time
+// and otherwise the start of the next window.
+func nextMaintenanceWindow(windows []infrav1beta1.MaintenanceWindow, now time.Time) (bool, time.Time, error) {
+	var next time.Time
+
+	for _, window := range windows {
+		loc := time.UTC
+		if window.TimeZone != "" {
+			var err error
+			loc, err = time.LoadLocation(window.TimeZone)
+			if err != nil {
+				return false, next, fmt.Errorf("invalid maintenance window time zone %q: %w", window.TimeZone, err)
+			}
+		}
+
+		start, err := time.ParseInLocation("15:04", window.Start, loc)
+		if err != nil {
+			return false, next, fmt.Errorf("invalid maintenance window start %q: %w", window.Start, err)
+		}
+
+		local := now.In(loc)
+
+		// Start with the previous day to catch windows which started yesterday and span midnight
+		for day := -1; day <= 7; day++ {
+			date := local.AddDate(0, 0, day)
+			windowStart := time.Date(date.Year(), date.Month(), date.Day(), start.Hour(), start.Minute(), 0, 0, loc)
+
+			if len(window.Days) > 0 && !slices.Contains(window.Days, infrav1beta1.Weekday(windowStart.Weekday().String())) {
+				continue
+			}
+
+			if !now.Before(windowStart) && now.Before(windowStart.Add(window.Duration.Duration)) {
+				return true, windowStart, nil
+			}
+
+			if windowStart.After(now) && (next.IsZero() || windowStart.Before(next)) {
+				next = windowStart
+			}
+		}
+	}
+
+	if next.IsZero() {
+		return false, next, fmt.Errorf("no upcoming maintenance window found")
+	}
+
+	return false, next, nil
+}
+
+// scheduledAt returns the maintenance window the planned changes are deferred to
+func scheduledAt(changes []infrav1beta1.PlannedChange) *metav1.Time {
+	for _, change := range changes {
+		if change.ScheduledAt != nil {
+			return change.ScheduledAt
+		}
+	}
+
+	return nil
+}
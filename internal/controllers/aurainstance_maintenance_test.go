@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("AuraInstance maintenance windows", func() {
+	// 2025-06-04 is a Wednesday
+	at := func(value string) time.Time {
+		t, err := time.Parse(time.RFC3339, value)
+		Expect(err).NotTo(HaveOccurred())
+		return t
+	}
+
+	It("is open within a daily window", func() {
+		open, start, err := nextMaintenanceWindow([]v1beta1.MaintenanceWindow{
+			{Start: "02:00", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+		}, at("2025-06-04T03:00:00Z"))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(open).To(BeTrue())
+		Expect(start).To(Equal(at("2025-06-04T02:00:00Z")))
+	})
+
+	It("returns the next daily window", func() {
+		open, start, err := nextMaintenanceWindow([]v1beta1.MaintenanceWindow{
+			{Start: "02:00", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+		}, at("2025-06-04T04:00:00Z"))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(open).To(BeFalse())
+		Expect(start).To(Equal(at("2025-06-05T02:00:00Z")))
+	})
+
+	It("is open within a window which started the day before", func() {
+		open, _, err := nextMaintenanceWindow([]v1beta1.MaintenanceWindow{
+			{Days: []v1beta1.Weekday{"Tuesday"}, Start: "23:00", Duration: metav1.Duration{Duration: 3 * time.Hour}},
+		}, at("2025-06-04T01:00:00Z"))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(open).To(BeTrue())
+	})
+
+	It("returns the earliest window on the configured days", func() {
+		open, start, err := nextMaintenanceWindow([]v1beta1.MaintenanceWindow{
+			{Days: []v1beta1.Weekday{"Sunday"}, Start: "02:00", Duration: metav1.Duration{Duration: time.Hour}},
+			{Days: []v1beta1.Weekday{"Saturday"}, Start: "22:00", Duration: metav1.Duration{Duration: time.Hour}},
+		}, at("2025-06-04T12:00:00Z"))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(open).To(BeFalse())
+		Expect(start).To(Equal(at("2025-06-07T22:00:00Z")))
+	})
+
+	It("respects the time zone of the window", func() {
+		open, start, err := nextMaintenanceWindow([]v1beta1.MaintenanceWindow{
+			{Start: "02:00", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Europe/Zurich"},
+		}, at("2025-06-04T01:30:00Z"))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(open).To(BeFalse())
+		Expect(start.UTC()).To(Equal(at("2025-06-05T00:00:00Z")))
+	})
+
+	It("fails for an invalid time zone", func() {
+		_, _, err := nextMaintenanceWindow([]v1beta1.MaintenanceWindow{
+			{Start: "02:00", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Mars/Olympus"},
+		}, at("2025-06-04T01:30:00Z"))
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("only defers patches and upgrades", func() {
+		Expect(requiresMaintenanceWindow(operation{PlannedChange: v1beta1.PlannedChange{Operation: v1beta1.AuraOperationPatch}})).To(BeTrue())
+		Expect(requiresMaintenanceWindow(operation{PlannedChange: v1beta1.PlannedChange{Operation: v1beta1.AuraOperationUpgrade}})).To(BeTrue())
+		Expect(requiresMaintenanceWindow(operation{PlannedChange: v1beta1.PlannedChange{Operation: v1beta1.AuraOperationResume}})).To(BeFalse())
+		Expect(requiresMaintenanceWindow(operation{PlannedChange: v1beta1.PlannedChange{Operation: v1beta1.AuraOperationCreate}})).To(BeFalse())
+	})
+})
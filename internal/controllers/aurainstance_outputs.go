@@ -0,0 +1,240 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	infrav1beta1 "github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// errNotControlled is returned if an object with the name of an output exists which is not controlled by the instance
+var errNotControlled = errors.New("object is not controlled by the instance")
+
+// outputName returns the name of an output object, defaults to the instance name
+func outputName(instance infrav1beta1.AuraInstance, output *infrav1beta1.OutputObject) string {
+	if output == nil {
+		return ""
+	}
+
+	if output.Name != "" {
+		return output.Name
+	}
+
+	return instance.Name
+}
+
+// connectionConfigMapData returns the non-sensitive connection details
+func connectionConfigMapData(details connectionDetails) map[string]string {
+	return map[string]string{
+		"instanceID":    details.InstanceID,
+		"name":          details.Name,
+		"connectionURL": details.ConnectionURL,
+		"scheme":        details.Scheme,
+		"host":          details.Host,
+		"port":          details.Port,
+		"region":        details.Region,
+		"cloudProvider": details.CloudProvider,
+		"tier":          details.Tier,
+		"version":       details.Version,
+	}
+}
+
+// reconcileOutputs publishes the non-sensitive connection details to the configured ConfigMap and Service.
+// Outputs which have been removed or renamed are deleted.
+func (r *AuraInstanceReconciler) reconcileOutputs(ctx context.Context, instance infrav1beta1.AuraInstance, connectionURL string) (infrav1beta1.AuraInstance, error) {
+	var configMapOutput, serviceOutput *infrav1beta1.OutputObject
+	if instance.Spec.Outputs != nil {
+		configMapOutput = instance.Spec.Outputs.ConfigMap
+		serviceOutput = instance.Spec.Outputs.Service
+	}
+
+	configMapName := outputName(instance, configMapOutput)
+	serviceName := outputName(instance, serviceOutput)
+
+	if instance.Status.ConfigMap != "" && instance.Status.ConfigMap != configMapName {
+		if err := r.deleteOwnedObject(ctx, instance, &corev1.ConfigMap{}, instance.Status.ConfigMap); err != nil {
+			return instance, err
+		}
+
+		instance.Status.ConfigMap = ""
+	}
+
+	if instance.Status.Service != "" && instance.Status.Service != serviceName {
+		if err := r.deleteOwnedObject(ctx, instance, &corev1.Service{}, instance.Status.Service); err != nil {
+			return instance, err
+		}
+
+		instance.Status.Service = ""
+	}
+
+	if (configMapName == "" && serviceName == "") || connectionURL == "" {
+		return instance, nil
+	}
+
+	details, err := newConnectionDetails(instance, "", "", connectionURL)
+	if err != nil {
+		return instance, err
+	}
+
+	if configMapName != "" {
+		configMap := corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      configMapName,
+				Namespace: instance.Namespace,
+			},
+		}
+
+		err := r.writeOwnedObject(ctx, instance, &configMap, func() error {
+			configMap.Data = connectionConfigMapData(details)
+			return nil
+		})
+
+		if err != nil {
+			return instance, fmt.Errorf("failed to write connection configmap %s: %w", configMapName, err)
+		}
+
+		instance.Status.ConfigMap = configMapName
+	}
+
+	if serviceName != "" && details.Host != "" {
+		port, err := strconv.ParseInt(details.Port, 10, 32)
+		if err != nil {
+			return instance, fmt.Errorf("failed to parse connection url port: %w", err)
+		}
+
+		service := corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      serviceName,
+				Namespace: instance.Namespace,
+			},
+		}
+
+		err = r.writeOwnedObject(ctx, instance, &service, func() error {
+			service.Spec.Type = corev1.ServiceTypeExternalName
+			service.Spec.ExternalName = details.Host
+			service.Spec.Ports = []corev1.ServicePort{
+				{
+					Name:       "bolt",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       int32(port),
+					TargetPort: intstr.FromInt32(int32(port)),
+				},
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return instance, fmt.Errorf("failed to write connection service %s: %w", serviceName, err)
+		}
+
+		instance.Status.Service = serviceName
+	}
+
+	return instance, nil
+}
+
+// deleteOutputs deletes the published ConfigMap, Service and ScrapeConfig
+func (r *AuraInstanceReconciler) deleteOutputs(ctx context.Context, instance infrav1beta1.AuraInstance) (infrav1beta1.AuraInstance, error) {
+	if instance.Status.ConfigMap != "" {
+		if err := r.deleteOwnedObject(ctx, instance, &corev1.ConfigMap{}, instance.Status.ConfigMap); err != nil {
+			return instance, err
+		}
+
+		instance.Status.ConfigMap = ""
+	}
+
+	if instance.Status.Service != "" {
+		if err := r.deleteOwnedObject(ctx, instance, &corev1.Service{}, instance.Status.Service); err != nil {
+			return instance, err
+		}
+
+		instance.Status.Service = ""
+	}
+
+	if instance.Status.ScrapeConfig != "" {
+		if err := r.deleteOwnedObject(ctx, instance, newScrapeConfig(), instance.Status.ScrapeConfig); err != nil {
+			return instance, err
+		}
+
+		instance.Status.ScrapeConfig = ""
+	}
+
+	instance.Status.MetricsIntegrationURL = ""
+	return instance, nil
+}
+
+// writeOwnedObject creates or updates an object controlled by the instance.
+// Existing objects which don't belong to the instance are never taken over as they might be managed by someone else.
+func (r *AuraInstanceReconciler) writeOwnedObject(ctx context.Context, instance infrav1beta1.AuraInstance, obj client.Object, mutate func() error) error {
+	err := r.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+
+	if err == nil && !metav1.IsControlledBy(obj, &instance) {
+		return errNotControlled
+	}
+
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, obj, func() error {
+		if err := mutate(); err != nil {
+			return err
+		}
+
+		return controllerutil.SetControllerReference(&instance, obj, r.Scheme())
+	})
+
+	return err
+}
+
+// deleteOwnedObject deletes an object if it is controlled by the instance
+func (r *AuraInstanceReconciler) deleteOwnedObject(ctx context.Context, instance infrav1beta1.AuraInstance, obj client.Object, name string) error {
+	err := r.Get(ctx, client.ObjectKey{
+		Name:      name,
+		Namespace: instance.Namespace,
+	}, obj)
+
+	// Objects of optional CRDs which are not installed don't exist either
+	if kerrors.IsNotFound(err) || apimeta.IsNoMatchError(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", name, err)
+	}
+
+	if !metav1.IsControlledBy(obj, &instance) {
+		return nil
+	}
+
+	if err := r.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete %s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
+)
+
+var _ = Describe("AuraInstance outputs", func() {
+	It("publishes the connection details", func() {
+		ctx := context.Background()
+		name := fmt.Sprintf("outputs-%s", rand.String(5))
+
+		instance := &v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1beta1.AuraInstanceSpec{
+				TenantID:      "x",
+				Neo4jVersion:  "5",
+				Tier:          "free-db",
+				CloudProvider: "gcp",
+				Region:        "europe-west1",
+				Suspend:       true,
+				Outputs: &v1beta1.Outputs{
+					ConfigMap: &v1beta1.OutputObject{},
+					Service:   &v1beta1.OutputObject{Name: name + "-db"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, instance)).Should(Succeed())
+		instance.Status.InstanceID = "abc"
+
+		r := &AuraInstanceReconciler{
+			Client:   k8sClient,
+			Recorder: record.NewFakeRecorder(10),
+		}
+
+		By("creating the ConfigMap and Service")
+		published, err := r.reconcileOutputs(ctx, *instance, "neo4j+s://abc.databases.neo4j.io")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(published.Status.ConfigMap).To(Equal(name))
+		Expect(published.Status.Service).To(Equal(name + "-db"))
+
+		var configMap corev1.ConfigMap
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &configMap)).Should(Succeed())
+		Expect(configMap.Data).To(HaveKeyWithValue("instanceID", "abc"))
+		Expect(configMap.Data).To(HaveKeyWithValue("host", "abc.databases.neo4j.io"))
+		Expect(configMap.Data).To(HaveKeyWithValue("port", "7687"))
+		Expect(configMap.Data).To(HaveKeyWithValue("region", "europe-west1"))
+		Expect(configMap.Data).NotTo(HaveKey("password"))
+		Expect(metav1.IsControlledBy(&configMap, instance)).To(BeTrue())
+
+		var service corev1.Service
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name + "-db", Namespace: "default"}, &service)).Should(Succeed())
+		Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeExternalName))
+		Expect(service.Spec.ExternalName).To(Equal("abc.databases.neo4j.io"))
+		Expect(service.Spec.Ports[0].Port).To(Equal(int32(7687)))
+
+		By("deleting outputs which have been removed")
+		published.Spec.Outputs.Service = nil
+		published, err = r.reconcileOutputs(ctx, published, "neo4j+s://abc.databases.neo4j.io")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(published.Status.Service).To(BeEmpty())
+
+		err = k8sClient.Get(ctx, types.NamespacedName{Name: name + "-db", Namespace: "default"}, &service)
+		Expect(kerrors.IsNotFound(err)).To(BeTrue())
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &configMap)).Should(Succeed())
+	})
+
+	It("does not take over objects which don't belong to the instance", func() {
+		ctx := context.Background()
+		name := fmt.Sprintf("outputs-%s", rand.String(5))
+
+		instance := &v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1beta1.AuraInstanceSpec{
+				TenantID:      "x",
+				Neo4jVersion:  "5",
+				Tier:          "free-db",
+				CloudProvider: "gcp",
+				Region:        "europe-west1",
+				Suspend:       true,
+				Outputs: &v1beta1.Outputs{
+					Service: &v1beta1.OutputObject{},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, instance)).Should(Succeed())
+		instance.Status.InstanceID = "abc"
+
+		Expect(k8sClient.Create(ctx, &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+			},
+		})).Should(Succeed())
+
+		r := &AuraInstanceReconciler{
+			Client:   k8sClient,
+			Recorder: record.NewFakeRecorder(10),
+		}
+
+		published, err := r.reconcileOutputs(ctx, *instance, "neo4j+s://abc.databases.neo4j.io")
+		Expect(err).To(MatchError(errNotControlled))
+		Expect(published.Status.Service).To(BeEmpty())
+
+		published = setReconcileResult(published, err)
+		Expect(conditions.GetReason(&published, v1beta1.ConditionStalled)).To(Equal("OutputConflict"))
+
+		var service corev1.Service
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &service)).Should(Succeed())
+		Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+		Expect(service.OwnerReferences).To(BeEmpty())
+	})
+})
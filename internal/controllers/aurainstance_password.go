@@ -0,0 +1,242 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+
+	infrav1beta1 "github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/bolt"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// credentialsPendingPasswordKey holds a new password in the credentials backup until the rotation is confirmed
+	credentialsPendingPasswordKey = "pendingPassword"
+
+	alterPasswordQuery = "ALTER CURRENT USER SET PASSWORD FROM $old TO $new"
+	passwordAlphabet   = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	passwordLength     = 32
+
+	// passwordRotationRetryInterval is used to retry failed rotations
+	passwordRotationRetryInterval = 5 * time.Minute
+)
+
+// generatePassword returns a random alphanumeric password
+func generatePassword() (string, error) {
+	password := make([]byte, passwordLength)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(passwordAlphabet))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate password: %w", err)
+		}
+
+		password[i] = passwordAlphabet[n.Int64()]
+	}
+
+	return string(password), nil
+}
+
+// nextPasswordRotation returns the duration until the password needs to be rotated, zero if it is due
+func nextPasswordRotation(instance infrav1beta1.AuraInstance, now time.Time) time.Duration {
+	if instance.Status.LastPasswordRotation == nil {
+		return 0
+	}
+
+	next := instance.Status.LastPasswordRotation.Add(instance.Spec.PasswordRotation.Interval.Duration)
+	if !now.Before(next) {
+		return 0
+	}
+
+	return next.Sub(now)
+}
+
+// rotatePassword changes the password of the current user.
+// The change is rolled back if the new password can't be used to authenticate.
+func rotatePassword(ctx context.Context, dialer bolt.Dialer, url, username, current, next string) error {
+	conn, err := dialer.Dial(ctx, url, bolt.Auth{Username: username, Password: current})
+	if err != nil {
+		return fmt.Errorf("failed to connect to instance: %w", err)
+	}
+
+	defer func() {
+		_ = conn.Close(ctx)
+	}()
+
+	if _, err := conn.Run(ctx, bolt.SystemDatabase, alterPasswordQuery, map[string]any{
+		"old": current,
+		"new": next,
+	}); err != nil {
+		return fmt.Errorf("failed to change password: %w", err)
+	}
+
+	verify, err := dialer.Dial(ctx, url, bolt.Auth{Username: username, Password: next})
+	if err == nil {
+		_ = verify.Close(ctx)
+		return nil
+	}
+
+	// The existing connection is still authenticated and is used to restore the previous password
+	if _, rollbackErr := conn.Run(ctx, bolt.SystemDatabase, alterPasswordQuery, map[string]any{
+		"old": next,
+		"new": current,
+	}); rollbackErr != nil {
+		return fmt.Errorf("failed to authenticate with the new password: %w, rollback failed: %w", err, rollbackErr)
+	}
+
+	return fmt.Errorf("failed to authenticate with the new password, rolled back: %w", err)
+}
+
+// verifyPassword returns true if the credentials can be used to authenticate
+func verifyPassword(ctx context.Context, dialer bolt.Dialer, url, username, password string) (bool, error) {
+	conn, err := dialer.Dial(ctx, url, bolt.Auth{Username: username, Password: password})
+	if bolt.IsUnauthorized(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	_ = conn.Close(ctx)
+	return true, nil
+}
+
+// reconcilePasswordRotation rotates the admin password if the rotation is due.
+// It returns the duration until the next rotation.
+func (r *AuraInstanceReconciler) reconcilePasswordRotation(ctx context.Context, instance infrav1beta1.AuraInstance, connectionURL string, logger logr.Logger) (infrav1beta1.AuraInstance, time.Duration, error) {
+	if instance.Spec.PasswordRotation == nil || instance.Spec.PasswordRotation.Interval.Duration <= 0 {
+		conditions.Delete(&instance, infrav1beta1.ConditionPasswordRotated)
+		return instance, 0, nil
+	}
+
+	if r.isDryRun(instance) {
+		return instance, 0, nil
+	}
+
+	var backup corev1.Secret
+	err := r.Get(ctx, types.NamespacedName{
+		Name:      credentialsBackupName(instance),
+		Namespace: instance.Namespace,
+	}, &backup)
+
+	if kerrors.IsNotFound(err) {
+		instance = infrav1beta1.AuraInstancePasswordRotated(instance, metav1.ConditionFalse, "CredentialsUnavailable", "The password can't be rotated without a credentials backup")
+		return instance, 0, nil
+	}
+
+	if err != nil {
+		return instance, 0, fmt.Errorf("failed to get credentials backup: %w", err)
+	}
+
+	username := string(backup.Data[credentialsUsernameKey])
+	current := string(backup.Data[credentialsPasswordKey])
+
+	// A pending password is left behind if a previous rotation was interrupted
+	if pending, ok := backup.Data[credentialsPendingPasswordKey]; ok {
+		valid, err := verifyPassword(ctx, r.BoltDialer, connectionURL, username, string(pending))
+		if err != nil {
+			return instance, 0, fmt.Errorf("failed to verify pending password: %w", err)
+		}
+
+		if valid {
+			logger.Info("pending password is valid, completing password rotation")
+			return r.completePasswordRotation(ctx, instance, backup, string(pending), connectionURL, logger)
+		}
+
+		valid, err = verifyPassword(ctx, r.BoltDialer, connectionURL, username, current)
+		if err != nil {
+			return instance, 0, fmt.Errorf("failed to verify password: %w", err)
+		}
+
+		if !valid {
+			instance = infrav1beta1.AuraInstancePasswordRotated(instance, metav1.ConditionFalse, "CredentialsInvalid", "Neither the current nor the pending password can be used to authenticate")
+			return instance, passwordRotationRetryInterval, nil
+		}
+
+		delete(backup.Data, credentialsPendingPasswordKey)
+		if err := r.Update(ctx, &backup); err != nil {
+			return instance, 0, fmt.Errorf("failed to update credentials backup: %w", err)
+		}
+	}
+
+	if next := nextPasswordRotation(instance, time.Now()); next > 0 {
+		return instance, next, nil
+	}
+
+	password, err := generatePassword()
+	if err != nil {
+		return instance, 0, err
+	}
+
+	// The new password is stored before it is set, otherwise it would be lost if the controller stops in between
+	backup.Data[credentialsPendingPasswordKey] = []byte(password)
+	if err := r.Update(ctx, &backup); err != nil {
+		return instance, 0, fmt.Errorf("failed to update credentials backup: %w", err)
+	}
+
+	logger.Info("rotating password")
+	if err := rotatePassword(ctx, r.BoltDialer, connectionURL, username, current, password); err != nil {
+		logger.Error(err, "failed to rotate password")
+		instance = infrav1beta1.AuraInstancePasswordRotated(instance, metav1.ConditionFalse, "RotationFailed", err.Error())
+		r.Recorder.Event(&instance, "Warning", "PasswordRotationFailed", err.Error())
+		return instance, passwordRotationRetryInterval, nil
+	}
+
+	return r.completePasswordRotation(ctx, instance, backup, password, connectionURL, logger)
+}
+
+// completePasswordRotation persists the new password and updates the connection secret including its replicas and sinks
+func (r *AuraInstanceReconciler) completePasswordRotation(ctx context.Context, instance infrav1beta1.AuraInstance, backup corev1.Secret, password, connectionURL string, logger logr.Logger) (infrav1beta1.AuraInstance, time.Duration, error) {
+	backup.Data[credentialsPasswordKey] = []byte(password)
+	delete(backup.Data, credentialsPendingPasswordKey)
+
+	if err := r.Update(ctx, &backup); err != nil {
+		return instance, 0, fmt.Errorf("failed to update credentials backup: %w", err)
+	}
+
+	// The rotation is completed once the backup holds the new password, copies which fail to be updated are retried
+	now := metav1.Now()
+	instance.Status.LastPasswordRotation = &now
+	instance = infrav1beta1.AuraInstancePasswordRotated(instance, metav1.ConditionTrue, "PasswordRotated", "Password has been rotated")
+
+	if err := r.reconcileConnectionSecret(ctx, instance, connectionURL, logger); err != nil {
+		return instance, 0, err
+	}
+
+	// The replicas and sinks have been synced with the previous password earlier in this reconciliation
+	instance, err := r.reconcileReplicas(ctx, instance, logger)
+	if err != nil {
+		return instance, 0, err
+	}
+
+	if err := r.reconcileSinks(ctx, instance); err != nil {
+		return instance, 0, err
+	}
+
+	r.Recorder.Event(&instance, "Normal", "PasswordRotated", "Password has been rotated")
+	return instance, instance.Spec.PasswordRotation.Interval.Duration, nil
+}
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("AuraInstance password rotation", func() {
+	It("generates random passwords", func() {
+		first, err := generatePassword()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(first).To(HaveLen(passwordLength))
+		Expect(first).To(MatchRegexp("^[a-zA-Z0-9]+$"))
+
+		second, err := generatePassword()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).NotTo(Equal(first))
+	})
+
+	It("rotates the password", func() {
+		dialer := &fakeDialer{password: "old"}
+		Expect(rotatePassword(context.TODO(), dialer, "neo4j+s://abc", "neo4j", "old", "new")).To(Succeed())
+		Expect(dialer.password).To(Equal("new"))
+		Expect(dialer.queries).To(Equal([]string{alterPasswordQuery}))
+	})
+
+	It("fails if the current password is invalid", func() {
+		dialer := &fakeDialer{password: "other"}
+		Expect(rotatePassword(context.TODO(), dialer, "neo4j+s://abc", "neo4j", "old", "new")).NotTo(Succeed())
+		Expect(dialer.password).To(Equal("other"))
+	})
+
+	It("fails if the password can't be changed", func() {
+		dialer := &fakeDialer{password: "old", runErr: errors.New("forbidden")}
+		Expect(rotatePassword(context.TODO(), dialer, "neo4j+s://abc", "neo4j", "old", "new")).NotTo(Succeed())
+		Expect(dialer.password).To(Equal("old"))
+	})
+
+	It("rolls back if the new password can't authenticate", func() {
+		dialer := &fakeDialer{password: "old", rejectPasswords: []string{"new"}}
+		err := rotatePassword(context.TODO(), dialer, "neo4j+s://abc", "neo4j", "old", "new")
+		Expect(err).To(MatchError(ContainSubstring("rolled back")))
+		Expect(dialer.password).To(Equal("old"))
+		Expect(dialer.queries).To(Equal([]string{alterPasswordQuery, alterPasswordQuery}))
+	})
+
+	It("updates every copy of the connection secret", func() {
+		ctx := context.Background()
+		name := fmt.Sprintf("rotation-%s", rand.String(5))
+		namespace := fmt.Sprintf("team-%s", rand.String(5))
+		connectionURL := "neo4j+s://abc.databases.neo4j.io"
+
+		var written map[string]string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == "/v1/kv/data/apps/"+name:
+				w.WriteHeader(http.StatusNotFound)
+			case r.Method == http.MethodPost && r.URL.Path == "/v1/kv/data/apps/"+name:
+				var body struct {
+					Data map[string]string `json:"data"`
+				}
+
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				written = body.Data
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})).Should(Succeed())
+		Expect(k8sClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name + "-vault",
+				Namespace: "default",
+			},
+			StringData: map[string]string{"token": "root"},
+		})).Should(Succeed())
+
+		instance := &v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1beta1.AuraInstanceSpec{
+				TenantID:      "x",
+				Neo4jVersion:  "5",
+				Tier:          "free-db",
+				CloudProvider: "gcp",
+				Suspend:       true,
+				PasswordRotation: &v1beta1.PasswordRotation{
+					Interval: metav1.Duration{Duration: time.Hour},
+				},
+				ConnectionSecret: v1beta1.ConnectionSecretSpec{
+					ReplicateTo: &v1beta1.SecretReplication{
+						Namespaces: []string{namespace},
+					},
+					Sink: &v1beta1.ConnectionSecretSink{
+						Vault: &v1beta1.VaultSink{
+							Address:     server.URL,
+							Mount:       "kv",
+							Path:        "apps/" + name,
+							TokenSecret: v1beta1.LocalObjectReference{Name: name + "-vault"},
+						},
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, instance)).Should(Succeed())
+
+		dialer := &fakeDialer{password: "old"}
+		r := &AuraInstanceReconciler{
+			Client:                       k8sClient,
+			Recorder:                     record.NewFakeRecorder(10),
+			BoltDialer:                   dialer,
+			ReplicationAllowedNamespaces: []string{"team-*"},
+		}
+
+		By("syncing the copies with the current password")
+		Expect(r.writeCredentialsBackup(ctx, *instance, "neo4j", "old", connectionURL)).To(Succeed())
+		Expect(r.reconcileConnectionSecret(ctx, *instance, connectionURL, ctrl.Log)).To(Succeed())
+		synced, err := r.reconcileReplicas(ctx, *instance, ctrl.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.reconcileSinks(ctx, synced)).To(Succeed())
+		Expect(written).To(HaveKeyWithValue("password", "old"))
+
+		By("rotating the password")
+		rotated, next, err := r.reconcilePasswordRotation(ctx, synced, connectionURL, ctrl.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next).To(Equal(time.Hour))
+		Expect(rotated.Status.LastPasswordRotation).NotTo(BeNil())
+		Expect(dialer.password).NotTo(Equal("old"))
+
+		for _, key := range []types.NamespacedName{
+			{Name: credentialsBackupName(*instance), Namespace: "default"},
+			{Name: connectionSecretName(*instance), Namespace: "default"},
+			{Name: connectionSecretName(*instance), Namespace: namespace},
+		} {
+			var secret corev1.Secret
+			Expect(k8sClient.Get(ctx, key, &secret)).Should(Succeed())
+			Expect(secret.Data).To(HaveKeyWithValue("password", []byte(dialer.password)), key.String())
+		}
+
+		Expect(written).To(HaveKeyWithValue("password", dialer.password))
+	})
+
+	It("schedules the next rotation", func() {
+		now := time.Now()
+		instance := v1beta1.AuraInstance{
+			Spec: v1beta1.AuraInstanceSpec{
+				PasswordRotation: &v1beta1.PasswordRotation{
+					Interval: metav1.Duration{Duration: time.Hour},
+				},
+			},
+		}
+
+		Expect(nextPasswordRotation(instance, now)).To(BeZero())
+
+		instance.Status.LastPasswordRotation = &metav1.Time{Time: now.Add(-time.Minute)}
+		Expect(nextPasswordRotation(instance, now)).To(Equal(59 * time.Minute))
+
+		instance.Status.LastPasswordRotation = &metav1.Time{Time: now.Add(-2 * time.Hour)}
+		Expect(nextPasswordRotation(instance, now)).To(BeZero())
+	})
+})
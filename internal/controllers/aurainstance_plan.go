@@ -0,0 +1,238 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	infrav1beta1 "github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
+	"github.com/fluxcd/pkg/runtime/conditions"
+)
+
+// operation is a planned mutating Aura API call including its request body
+type operation struct {
+	infrav1beta1.PlannedChange
+	create  auraclient.PostInstancesJSONRequestBody
+	patch   auraclient.PatchInstanceIdJSONRequestBody
+	upgrade auraclient.PostUpgradeInstanceJSONRequestBody
+}
+
+func (o operation) String() string {
+	if len(o.Changes) == 0 {
+		return fmt.Sprintf("%s %s", o.Operation, o.Request)
+	}
+
+	return fmt.Sprintf("%s %s (%s)", o.Operation, o.Request, strings.Join(o.Changes, ", "))
+}
+
+// plan is an ordered list of operations required to reach the desired state
+type plan []operation
+
+func (p plan) changes() []infrav1beta1.PlannedChange {
+	if len(p) == 0 {
+		return nil
+	}
+
+	changes := make([]infrav1beta1.PlannedChange, 0, len(p))
+	for _, op := range p {
+		changes = append(changes, op.PlannedChange)
+	}
+
+	return changes
+}
+
+// disruptive returns the operations which require an approval
+func (p plan) disruptive() plan {
+	var ops plan
+	for _, op := range p {
+		if op.Disruptive {
+			ops = append(ops, op)
+		}
+	}
+
+	return ops
+}
+
+// hash returns a stable hash of the planned changes or an empty string if there are none
+func (p plan) hash() string {
+	if len(p) == 0 {
+		return ""
+	}
+
+	b, _ := json.Marshal(p.changes())
+	return fmt.Sprintf("%x", sha256.Sum256(b))[:16]
+}
+
+func (p plan) String() string {
+	ops := make([]string, 0, len(p))
+	for _, op := range p {
+		ops = append(ops, op.String())
+	}
+
+	return strings.Join(ops, "; ")
+}
+
+// planCreate plans the creation of a new Aura instance
+func planCreate(instance infrav1beta1.AuraInstance) plan {
+	op := operation{
+		PlannedChange: infrav1beta1.PlannedChange{
+			Operation: infrav1beta1.AuraOperationCreate,
+			Request:   "POST /instances",
+			Changes: []string{
+				fmt.Sprintf("%s: %s", driftFieldName, instance.Name),
+				fmt.Sprintf("%s: %s", driftFieldTier, instance.Spec.Tier),
+				fmt.Sprintf("%s: %s", driftFieldRegion, instance.Spec.Region),
+				fmt.Sprintf("%s: %s", driftFieldCloudProvider, instance.Spec.CloudProvider),
+				fmt.Sprintf("%s: %s", driftFieldMemory, instance.Spec.Memory),
+				fmt.Sprintf("version: %s", instance.Spec.Neo4jVersion),
+				fmt.Sprintf("%s: %s", driftFieldVectorOptimized, strconv.FormatBool(instance.Spec.VectorOptimized)),
+				fmt.Sprintf("%s: %s", driftFieldGraphAnalyticsPlugin, strconv.FormatBool(instance.Spec.GraphAnalyticsPlugin)),
+			},
+		},
+		create: auraclient.PostInstancesJSONRequestBody{
+			CloudProvider:        auraclient.CloudProvider(instance.Spec.CloudProvider),
+			Memory:               auraclient.InstanceMemory(instance.Spec.Memory),
+			Name:                 instance.Name,
+			Region:               auraclient.InstanceRegion(instance.Spec.Region),
+			TenantId:             instance.Spec.TenantID,
+			Type:                 auraclient.InstanceType(instance.Spec.Tier),
+			Version:              auraclient.InstanceVersion(instance.Spec.Neo4jVersion),
+			VectorOptimized:      &instance.Spec.VectorOptimized,
+			GraphAnalyticsPlugin: &instance.Spec.GraphAnalyticsPlugin,
+		},
+	}
+
+	return plan{op}
+}
+
+// planDrift plans the operations which correct the given drift.
+// Drift which can't be corrected in place (region, cloud provider and unsupported tier changes) is not planned.
+func planDrift(instance infrav1beta1.AuraInstance, remote *auraclient.Instance, drifts driftList) plan {
+	var p plan
+	changeOf := func(d drift) string {
+		return fmt.Sprintf("%s: %s -> %s", d.Field, d.Actual, d.Desired)
+	}
+
+	// A paused instance needs to be resumed before any other change can be applied
+	for _, d := range drifts {
+		if d.Field == driftFieldStatus {
+			p = append(p, operation{
+				PlannedChange: infrav1beta1.PlannedChange{
+					Operation: infrav1beta1.AuraOperationResume,
+					Request:   fmt.Sprintf("POST /instances/%s/resume", instance.Status.InstanceID),
+					Changes:   []string{changeOf(d)},
+				},
+			})
+		}
+	}
+
+	upgrade := drifts.has(driftFieldTier) && isTierUpgrade(instance, remote)
+	if upgrade {
+		op := operation{
+			PlannedChange: infrav1beta1.PlannedChange{
+				Operation:  infrav1beta1.AuraOperationUpgrade,
+				Request:    fmt.Sprintf("POST /instances/%s/upgrade", instance.Status.InstanceID),
+				Disruptive: true,
+			},
+		}
+
+		for _, d := range drifts {
+			switch d.Field {
+			case driftFieldTier:
+				op.Changes = append(op.Changes, changeOf(d))
+			case driftFieldMemory:
+				op.Changes = append(op.Changes, changeOf(d))
+				op.upgrade.Memory = &instance.Spec.Memory
+			}
+		}
+
+		p = append(p, op)
+	}
+
+	patch := operation{
+		PlannedChange: infrav1beta1.PlannedChange{
+			Operation: infrav1beta1.AuraOperationPatch,
+			Request:   fmt.Sprintf("PATCH /instances/%s", instance.Status.InstanceID),
+		},
+	}
+
+	for _, d := range drifts {
+		switch d.Field {
+		case driftFieldName:
+			patch.patch.Name = &instance.Name
+		case driftFieldMemory:
+			// The memory is already changed as part of the upgrade
+			if upgrade {
+				continue
+			}
+
+			memory := auraclient.InstanceMemory(instance.Spec.Memory)
+			patch.patch.Memory = &memory
+			patch.Disruptive = patch.Disruptive || isMemoryDownsize(d.Actual, d.Desired)
+		case driftFieldVectorOptimized:
+			patch.patch.VectorOptimized = &instance.Spec.VectorOptimized
+		case driftFieldGraphAnalyticsPlugin:
+			patch.patch.GraphAnalyticsPlugin = &instance.Spec.GraphAnalyticsPlugin
+		default:
+			continue
+		}
+
+		patch.Changes = append(patch.Changes, changeOf(d))
+	}
+
+	if len(patch.Changes) > 0 {
+		p = append(p, patch)
+	}
+
+	return p
+}
+
+// isMemoryDownsize returns true if the desired memory is smaller than the current memory.
+// Unknown memory sizes are treated as a downsize.
+func isMemoryDownsize(actual, desired string) bool {
+	actualGB, err := parseMemoryGB(actual)
+	if err != nil {
+		return true
+	}
+
+	desiredGB, err := parseMemoryGB(desired)
+	if err != nil {
+		return true
+	}
+
+	return desiredGB < actualGB
+}
+
+// parseMemoryGB parses an Aura memory size like 8GB
+func parseMemoryGB(memory string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(memory)), "GB"), 64)
+}
+
+// setPlan records the planned changes in the instance status
+func setPlan(instance *infrav1beta1.AuraInstance, p plan) {
+	instance.Status.PlannedChanges = p.changes()
+	instance.Status.PlannedChangesHash = p.disruptive().hash()
+
+	if instance.Status.PlannedChangesHash == "" {
+		conditions.Delete(instance, infrav1beta1.ConditionPendingApproval)
+	}
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("AuraInstance plan", func() {
+	instance := v1beta1.AuraInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "instance",
+		},
+		Spec: v1beta1.AuraInstanceSpec{
+			Tier:         v1beta1.AuraInstanceTierProfessionalDb,
+			Memory:       "8GB",
+			Neo4jVersion: "5",
+		},
+		Status: v1beta1.AuraInstanceStatus{
+			InstanceID: "abc",
+		},
+	}
+
+	It("plans the creation of an instance", func() {
+		p := planCreate(instance)
+		Expect(p).To(HaveLen(1))
+		Expect(p[0].Operation).To(Equal(v1beta1.AuraOperationCreate))
+		Expect(p[0].Request).To(Equal("POST /instances"))
+		Expect(p[0].create.Name).To(Equal("instance"))
+		Expect(p[0].create.Memory).To(Equal("8GB"))
+		Expect(p[0].Changes).To(ContainElement("memory: 8GB"))
+	})
+
+	It("plans nothing without drift", func() {
+		Expect(planDrift(instance, &auraclient.Instance{}, nil)).To(BeEmpty())
+	})
+
+	It("does not plan drift which can't be corrected", func() {
+		Expect(planDrift(instance, &auraclient.Instance{}, driftList{
+			{Field: driftFieldRegion, Desired: "europe-west1", Actual: "us-central1"},
+		})).To(BeEmpty())
+	})
+
+	It("plans a resume before patching the instance", func() {
+		p := planDrift(instance, &auraclient.Instance{}, driftList{
+			{Field: driftFieldMemory, Desired: "8GB", Actual: "4GB"},
+			{Field: driftFieldStatus, Desired: "running", Actual: "paused"},
+		})
+
+		Expect(p.changes()).To(Equal([]v1beta1.PlannedChange{
+			{
+				Operation: v1beta1.AuraOperationResume,
+				Request:   "POST /instances/abc/resume",
+				Changes:   []string{"status: paused -> running"},
+			},
+			{
+				Operation: v1beta1.AuraOperationPatch,
+				Request:   "PATCH /instances/abc",
+				Changes:   []string{"memory: 4GB -> 8GB"},
+			},
+		}))
+		Expect(*p[1].patch.Memory).To(Equal("8GB"))
+		Expect(p[1].patch.Name).To(BeNil())
+		Expect(p.String()).To(Equal("Resume POST /instances/abc/resume (status: paused -> running); Patch PATCH /instances/abc (memory: 4GB -> 8GB)"))
+	})
+
+	It("changes the memory as part of a tier upgrade", func() {
+		remote := &auraclient.Instance{}
+		remote.Data.Type = auraclient.InstanceTypeFreeDb
+
+		p := planDrift(instance, remote, driftList{
+			{Field: driftFieldTier, Desired: "professional-db", Actual: "free-db"},
+			{Field: driftFieldMemory, Desired: "8GB", Actual: "1GB"},
+			{Field: driftFieldVectorOptimized, Desired: "false", Actual: "true"},
+		})
+
+		Expect(p).To(HaveLen(2))
+		Expect(p[0].Operation).To(Equal(v1beta1.AuraOperationUpgrade))
+		Expect(*p[0].upgrade.Memory).To(Equal("8GB"))
+		Expect(p[1].Operation).To(Equal(v1beta1.AuraOperationPatch))
+		Expect(p[1].patch.Memory).To(BeNil())
+		Expect(p[1].Changes).To(Equal([]string{"vectorOptimized: true -> false"}))
+	})
+
+	It("marks memory downsizing as disruptive", func() {
+		p := planDrift(instance, &auraclient.Instance{}, driftList{
+			{Field: driftFieldMemory, Desired: "8GB", Actual: "16GB"},
+		})
+
+		Expect(p).To(HaveLen(1))
+		Expect(p[0].Disruptive).To(BeTrue())
+		Expect(p.disruptive()).To(HaveLen(1))
+		Expect(p.disruptive().hash()).To(HaveLen(16))
+	})
+
+	It("does not mark memory upsizing as disruptive", func() {
+		p := planDrift(instance, &auraclient.Instance{}, driftList{
+			{Field: driftFieldMemory, Desired: "8GB", Actual: "4GB"},
+		})
+
+		Expect(p[0].Disruptive).To(BeFalse())
+		Expect(p.disruptive()).To(BeEmpty())
+		Expect(p.disruptive().hash()).To(BeEmpty())
+	})
+
+	It("computes a stable hash of the planned changes", func() {
+		drifts := driftList{
+			{Field: driftFieldMemory, Desired: "8GB", Actual: "16GB"},
+		}
+
+		Expect(planDrift(instance, &auraclient.Instance{}, drifts).hash()).To(Equal(planDrift(instance, &auraclient.Instance{}, drifts).hash()))
+		Expect(planDrift(instance, &auraclient.Instance{}, drifts).hash()).NotTo(Equal(planDrift(instance, &auraclient.Instance{}, driftList{
+			{Field: driftFieldMemory, Desired: "8GB", Actual: "32GB"},
+		}).hash()))
+	})
+
+	It("treats unknown memory sizes as downsizing", func() {
+		Expect(isMemoryDownsize("16GB", "8GB")).To(BeTrue())
+		Expect(isMemoryDownsize("8GB", "16GB")).To(BeFalse())
+		Expect(isMemoryDownsize("8GB", "8GB")).To(BeFalse())
+		Expect(isMemoryDownsize("unknown", "8GB")).To(BeTrue())
+	})
+})
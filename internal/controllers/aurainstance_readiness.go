@@ -0,0 +1,90 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	infrav1beta1 "github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	readinessCheckQuery          = "RETURN 1"
+	defaultReadinessCheckTimeout = 10 * time.Second
+
+	// databaseReachableRetryInterval is the interval at which unreachable databases are checked again
+	databaseReachableRetryInterval = 30 * time.Second
+)
+
+// checkDatabaseReachable opens a Bolt session using the credentials of the connection secret and runs a trivial query.
+// Workloads are only able to connect if the credentials they consume are accepted.
+func (r *AuraInstanceReconciler) checkDatabaseReachable(ctx context.Context, instance infrav1beta1.AuraInstance) error {
+	timeout := defaultReadinessCheckTimeout
+	if instance.Spec.ReadinessCheck.Timeout != nil {
+		timeout = instance.Spec.ReadinessCheck.Timeout.Duration
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	url, auth, err := connectionSecretCredentials(ctx, r.Client, instance)
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.BoltDialer.Dial(ctx, url, auth)
+	if err != nil {
+		return fmt.Errorf("failed to connect to instance: %w", err)
+	}
+
+	defer func() {
+		_ = conn.Close(ctx)
+	}()
+
+	if _, err := conn.Run(ctx, "", readinessCheckQuery, nil); err != nil {
+		return fmt.Errorf("failed to execute %q: %w", readinessCheckQuery, err)
+	}
+
+	return nil
+}
+
+// reconcileDatabaseReachable verifies a running instance accepts Bolt connections if the readiness check is enabled.
+// It returns false if the instance is not reachable, the instance is marked as not ready but the reconciliation continues.
+func (r *AuraInstanceReconciler) reconcileDatabaseReachable(ctx context.Context, instance infrav1beta1.AuraInstance) (infrav1beta1.AuraInstance, bool) {
+	if instance.Spec.ReadinessCheck == nil || !instance.Spec.ReadinessCheck.Enabled {
+		conditions.Delete(&instance, infrav1beta1.ConditionDatabaseReachable)
+		return instance, true
+	}
+
+	if r.isDryRun(instance) {
+		return instance, true
+	}
+
+	if err := r.checkDatabaseReachable(ctx, instance); err != nil {
+		msg := fmt.Sprintf("Database is not reachable: %s", err)
+		instance = infrav1beta1.AuraInstanceDatabaseReachable(instance, metav1.ConditionFalse, "ConnectionFailed", msg)
+		instance = infrav1beta1.AuraInstanceReady(instance, metav1.ConditionFalse, "DatabaseUnreachable", msg)
+		return instance, false
+	}
+
+	instance = infrav1beta1.AuraInstanceDatabaseReachable(instance, metav1.ConditionTrue, "ConnectionSucceeded", "Database accepts Bolt connections")
+	return instance, true
+}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
+)
+
+var _ = Describe("AuraInstance readiness check", func() {
+	It("is skipped if disabled", func() {
+		instance := v1beta1.AuraInstanceDatabaseReachable(v1beta1.AuraInstance{}, metav1.ConditionTrue, "ConnectionSucceeded", "")
+		r := &AuraInstanceReconciler{}
+
+		instance, reachable := r.reconcileDatabaseReachable(context.TODO(), instance)
+		Expect(reachable).To(BeTrue())
+		Expect(conditions.Has(&instance, v1beta1.ConditionDatabaseReachable)).To(BeFalse())
+	})
+
+	It("verifies the database accepts Bolt connections", func() {
+		ctx := context.Background()
+		name := fmt.Sprintf("readiness-%s", rand.String(5))
+
+		instance := v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1beta1.AuraInstanceSpec{
+				ReadinessCheck: &v1beta1.ReadinessCheck{
+					Enabled: true,
+				},
+			},
+			Status: v1beta1.AuraInstanceStatus{
+				ObservedGeneration: 1,
+			},
+		}
+		instance.Generation = 2
+
+		dialer := &fakeDialer{password: "secret"}
+		r := &AuraInstanceReconciler{
+			Client:     k8sClient,
+			Recorder:   record.NewFakeRecorder(10),
+			BoltDialer: dialer,
+		}
+
+		By("failing without credentials")
+		instance, reachable := r.reconcileDatabaseReachable(ctx, instance)
+		Expect(reachable).To(BeFalse())
+		Expect(instance.Status.ObservedGeneration).To(Equal(int64(1)))
+		Expect(conditions.IsFalse(&instance, v1beta1.ConditionDatabaseReachable)).To(BeTrue())
+		Expect(conditions.GetReason(&instance, v1beta1.ConditionReady)).To(Equal("DatabaseUnreachable"))
+
+		for secretName, password := range map[string]string{
+			credentialsBackupName(instance): "stale",
+			connectionSecretName(instance):  "secret",
+		} {
+			Expect(k8sClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      secretName,
+					Namespace: "default",
+				},
+				StringData: map[string]string{
+					"username":      "neo4j",
+					"password":      password,
+					"connectionURL": "neo4j+s://abc.databases.neo4j.io",
+				},
+			})).Should(Succeed())
+		}
+
+		By("failing if the query fails")
+		dialer.runErr = errors.New("connection reset")
+		instance, reachable = r.reconcileDatabaseReachable(ctx, instance)
+		Expect(reachable).To(BeFalse())
+		Expect(conditions.GetMessage(&instance, v1beta1.ConditionDatabaseReachable)).To(ContainSubstring("connection reset"))
+
+		By("succeeding once the query succeeds")
+		dialer.runErr = nil
+		dialer.queries = nil
+		instance, reachable = r.reconcileDatabaseReachable(ctx, instance)
+		Expect(reachable).To(BeTrue())
+		Expect(conditions.IsTrue(&instance, v1beta1.ConditionDatabaseReachable)).To(BeTrue())
+		Expect(dialer.queries).To(Equal([]string{readinessCheckQuery}))
+	})
+
+	It("locates the credentials in a templated connection secret", func() {
+		instance := v1beta1.AuraInstance{
+			Spec: v1beta1.AuraInstanceSpec{
+				ConnectionSecret: v1beta1.ConnectionSecretSpec{
+					Template: &v1beta1.ConnectionSecretTemplate{
+						Data: map[string]string{
+							"NEO4J_USERNAME": "{{ .Username }}",
+							"NEO4J_PASSWORD": "{{ .Password }}",
+							"NEO4J_URI":      "{{ .ConnectionURL }}",
+							"NEO4J_HOST":     "{{ .Host }}",
+						},
+					},
+				},
+			},
+		}
+
+		username, password, connectionURL := connectionSecretCredentialKeys(instance)
+		Expect([]string{username, password, connectionURL}).To(Equal([]string{"NEO4J_USERNAME", "NEO4J_PASSWORD", "NEO4J_URI"}))
+
+		instance.Spec.ConnectionSecret.Template.Data = map[string]string{
+			"NEO4J_AUTH": "{{ .Username }}/{{ .Password }}",
+		}
+
+		username, password, connectionURL = connectionSecretCredentialKeys(instance)
+		Expect([]string{username, password, connectionURL}).To(Equal([]string{"", "", ""}))
+	})
+})
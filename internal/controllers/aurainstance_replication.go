@@ -0,0 +1,246 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	infrav1beta1 "github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// replicaLabels returns the labels which link a replicated connection secret to its instance
+func replicaLabels(instance infrav1beta1.AuraInstance) map[string]string {
+	return map[string]string{
+		infrav1beta1.InstanceNameLabel:      instance.Name,
+		infrav1beta1.InstanceNamespaceLabel: instance.Namespace,
+	}
+}
+
+// isReplicaOf reports whether the secret is a replicated connection secret of the instance
+func isReplicaOf(secret corev1.Secret, instance infrav1beta1.AuraInstance) bool {
+	return secret.Labels[infrav1beta1.InstanceNameLabel] == instance.Name &&
+		secret.Labels[infrav1beta1.InstanceNamespaceLabel] == instance.Namespace
+}
+
+// namespaceAllowed reports whether connection secrets may be replicated to the namespace.
+// The allow list contains namespace names or glob patterns, nothing is allowed if it is empty.
+func (r *AuraInstanceReconciler) namespaceAllowed(namespace string) bool {
+	for _, pattern := range r.ReplicationAllowedNamespaces {
+		if ok, _ := path.Match(pattern, namespace); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// replicationTargets returns the namespaces the connection secret is replicated to
+// and the selected namespaces which are not allowed.
+func (r *AuraInstanceReconciler) replicationTargets(ctx context.Context, instance infrav1beta1.AuraInstance) ([]string, []string, error) {
+	replicateTo := instance.Spec.ConnectionSecret.ReplicateTo
+	if replicateTo == nil {
+		return nil, nil, nil
+	}
+
+	namespaces := slices.Clone(replicateTo.Namespaces)
+	if replicateTo.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(replicateTo.NamespaceSelector)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid namespace selector: %w", err)
+		}
+
+		var list corev1.NamespaceList
+		if err := r.List(ctx, &list, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, nil, fmt.Errorf("failed to list namespaces: %w", err)
+		}
+
+		for _, namespace := range list.Items {
+			namespaces = append(namespaces, namespace.Name)
+		}
+	}
+
+	slices.Sort(namespaces)
+	namespaces = slices.Compact(namespaces)
+
+	var allowed, denied []string
+	for _, namespace := range namespaces {
+		switch {
+		case namespace == instance.Namespace:
+			continue
+		case r.namespaceAllowed(namespace):
+			allowed = append(allowed, namespace)
+		default:
+			denied = append(denied, namespace)
+		}
+	}
+
+	return allowed, denied, nil
+}
+
+// reconcileReplicas keeps copies of the connection secret in the selected namespaces
+// and deletes replicas in namespaces which are not selected anymore.
+func (r *AuraInstanceReconciler) reconcileReplicas(ctx context.Context, instance infrav1beta1.AuraInstance, logger logr.Logger) (infrav1beta1.AuraInstance, error) {
+	targets, denied, err := r.replicationTargets(ctx, instance)
+	if err != nil {
+		return instance, err
+	}
+
+	if len(denied) > 0 {
+		msg := fmt.Sprintf("Connection secret is not replicated to namespaces which are not allowed: %s", strings.Join(denied, ", "))
+		logger.Info(msg)
+		r.Recorder.Event(&instance, "Warning", "ReplicationDenied", msg)
+	}
+
+	var errs []error
+	var replicated []string
+
+	if len(targets) > 0 {
+		var source corev1.Secret
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      connectionSecretName(instance),
+			Namespace: instance.Namespace,
+		}, &source)
+
+		if err != nil {
+			return instance, fmt.Errorf("failed to get connection secret: %w", err)
+		}
+
+		for _, namespace := range targets {
+			if err := r.writeReplica(ctx, instance, source, namespace); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			replicated = append(replicated, namespace)
+		}
+	}
+
+	if err := r.deleteReplicas(ctx, instance, targets); err != nil {
+		errs = append(errs, err)
+	}
+
+	instance.Status.ReplicatedTo = replicated
+	return instance, errors.Join(errs...)
+}
+
+// writeReplica creates or updates the replicated connection secret in the namespace.
+// Existing secrets which are not replicas of the instance are never overwritten.
+func (r *AuraInstanceReconciler) writeReplica(ctx context.Context, instance infrav1beta1.AuraInstance, source corev1.Secret, namespace string) error {
+	replica := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      source.Name,
+			Namespace: namespace,
+		},
+	}
+
+	err := r.Get(ctx, client.ObjectKeyFromObject(&replica), &replica)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get replicated connection secret in namespace %s: %w", namespace, err)
+	}
+
+	if kerrors.IsNotFound(err) {
+		replica.Labels = replicaLabels(instance)
+		replica.Data = source.Data
+		if err := r.Create(ctx, &replica); err != nil {
+			return fmt.Errorf("failed to create replicated connection secret in namespace %s: %w", namespace, err)
+		}
+
+		return nil
+	}
+
+	if !isReplicaOf(replica, instance) {
+		return fmt.Errorf("failed to replicate connection secret, secret %s/%s already exists and is not managed by this instance", namespace, replica.Name)
+	}
+
+	if equality.Semantic.DeepEqual(replica.Data, source.Data) {
+		return nil
+	}
+
+	replica.Data = source.Data
+	if err := r.Update(ctx, &replica); err != nil {
+		return fmt.Errorf("failed to update replicated connection secret in namespace %s: %w", namespace, err)
+	}
+
+	return nil
+}
+
+// deleteReplicas deletes the replicated connection secrets except the ones in the given namespaces
+func (r *AuraInstanceReconciler) deleteReplicas(ctx context.Context, instance infrav1beta1.AuraInstance, keep []string) error {
+	var list corev1.SecretList
+	if err := r.List(ctx, &list, client.MatchingLabels(replicaLabels(instance))); err != nil {
+		return fmt.Errorf("failed to list replicated connection secrets: %w", err)
+	}
+
+	var errs []error
+	for _, replica := range list.Items {
+		if slices.Contains(keep, replica.Namespace) && replica.Name == connectionSecretName(instance) {
+			continue
+		}
+
+		if err := r.Delete(ctx, &replica); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("failed to delete replicated connection secret in namespace %s: %w", replica.Namespace, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// requestsForReplica returns the source instance of a replicated connection secret
+func requestsForReplica(secret *corev1.Secret) []reconcile.Request {
+	name, namespace := secret.Labels[infrav1beta1.InstanceNameLabel], secret.Labels[infrav1beta1.InstanceNamespaceLabel]
+	if name == "" || namespace == "" {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}}}
+}
+
+func (r *AuraInstanceReconciler) requestsForNamespaceChange(ctx context.Context, o client.Object) []reconcile.Request {
+	if _, ok := o.(*corev1.Namespace); !ok {
+		panic(fmt.Sprintf("expected a Namespace, got %T", o))
+	}
+
+	var list infrav1beta1.AuraInstanceList
+	if err := r.List(ctx, &list); err != nil {
+		return nil
+	}
+
+	var reqs []reconcile.Request
+	for _, instance := range list.Items {
+		if instance.Spec.ConnectionSecret.ReplicateTo == nil || instance.Spec.ConnectionSecret.ReplicateTo.NamespaceSelector == nil {
+			continue
+		}
+
+		r.Log.V(1).Info("namespace change for a replicating AuraInstance detected", "namespace", instance.GetNamespace(), "name", instance.GetName())
+		reqs = append(reqs, reconcile.Request{NamespacedName: objectKey(&instance)})
+	}
+
+	return reqs
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("AuraInstance connection secret replication", func() {
+	It("matches the allowed namespaces", func() {
+		r := &AuraInstanceReconciler{
+			ReplicationAllowedNamespaces: []string{"team-*", "shared"},
+		}
+
+		Expect(r.namespaceAllowed("team-a")).To(BeTrue())
+		Expect(r.namespaceAllowed("shared")).To(BeTrue())
+		Expect(r.namespaceAllowed("kube-system")).To(BeFalse())
+		Expect((&AuraInstanceReconciler{}).namespaceAllowed("team-a")).To(BeFalse())
+	})
+
+	It("replicates the connection secret and cleans up on deletion", func() {
+		ctx := context.Background()
+		name := fmt.Sprintf("replication-%s", rand.String(5))
+		allowed := fmt.Sprintf("team-%s", rand.String(5))
+		selected := fmt.Sprintf("team-%s", rand.String(5))
+		denied := fmt.Sprintf("other-%s", rand.String(5))
+
+		for _, namespace := range []string{allowed, selected, denied} {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+			if namespace == selected {
+				ns.Labels = map[string]string{"neo4j": name}
+			}
+
+			Expect(k8sClient.Create(ctx, ns)).Should(Succeed())
+		}
+
+		instance := &v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       name,
+				Namespace:  "default",
+				Finalizers: []string{v1beta1.Finalizer},
+			},
+			Spec: v1beta1.AuraInstanceSpec{
+				TenantID:      "x",
+				Neo4jVersion:  "5",
+				Tier:          "free-db",
+				CloudProvider: "gcp",
+				Suspend:       true,
+				ConnectionSecret: v1beta1.ConnectionSecretSpec{
+					ReplicateTo: &v1beta1.SecretReplication{
+						Namespaces: []string{allowed, denied},
+						NamespaceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"neo4j": name},
+						},
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, instance)).Should(Succeed())
+
+		r := &AuraInstanceReconciler{
+			Client:                       k8sClient,
+			Recorder:                     record.NewFakeRecorder(10),
+			ReplicationAllowedNamespaces: []string{"team-*"},
+		}
+
+		Expect(r.writeCredentialsBackup(ctx, *instance, "neo4j", "secret", "neo4j+s://abc.databases.neo4j.io")).To(Succeed())
+		Expect(r.reconcileConnectionSecret(ctx, *instance, "neo4j+s://abc.databases.neo4j.io", ctrl.Log)).To(Succeed())
+
+		By("replicating to the allowed namespaces")
+		replicated, err := r.reconcileReplicas(ctx, *instance, ctrl.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replicated.Status.ReplicatedTo).To(ConsistOf(allowed, selected))
+		Eventually(r.Recorder.(*record.FakeRecorder).Events).Should(Receive(ContainSubstring("ReplicationDenied")))
+
+		secretName := connectionSecretName(*instance)
+		for _, namespace := range []string{allowed, selected} {
+			var replica corev1.Secret
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: namespace}, &replica)).Should(Succeed())
+			Expect(replica.Data).To(HaveKeyWithValue("password", []byte("secret")))
+			Expect(replica.Labels).To(HaveKeyWithValue(v1beta1.InstanceNameLabel, name))
+		}
+
+		var secret corev1.Secret
+		err = k8sClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: denied}, &secret)
+		Expect(kerrors.IsNotFound(err)).To(BeTrue())
+
+		By("syncing changes of the connection secret")
+		Expect(r.writeCredentialsBackup(ctx, *instance, "neo4j", "rotated", "neo4j+s://abc.databases.neo4j.io")).To(Succeed())
+		Expect(r.reconcileConnectionSecret(ctx, *instance, "neo4j+s://abc.databases.neo4j.io", ctrl.Log)).To(Succeed())
+		_, err = r.reconcileReplicas(ctx, *instance, ctrl.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: allowed}, &secret)).Should(Succeed())
+		Expect(secret.Data).To(HaveKeyWithValue("password", []byte("rotated")))
+
+		By("deleting replicas of namespaces which are not selected anymore")
+		instance.Spec.ConnectionSecret.ReplicateTo.NamespaceSelector = nil
+		replicated, err = r.reconcileReplicas(ctx, *instance, ctrl.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replicated.Status.ReplicatedTo).To(Equal([]string{allowed}))
+		err = k8sClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: selected}, &secret)
+		Expect(kerrors.IsNotFound(err)).To(BeTrue())
+
+		By("deleting the replicas once the instance is deleted")
+		Expect(k8sClient.Delete(ctx, instance)).Should(Succeed())
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: allowed}, &secret)
+			return kerrors.IsNotFound(err)
+		}, 10*time.Second, 100*time.Millisecond).Should(BeTrue())
+
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, instance)
+			return kerrors.IsNotFound(err)
+		}, 10*time.Second, 100*time.Millisecond).Should(BeTrue())
+	})
+
+	It("does not overwrite existing secrets", func() {
+		ctx := context.Background()
+		name := fmt.Sprintf("replication-%s", rand.String(5))
+		namespace := fmt.Sprintf("team-%s", rand.String(5))
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})).Should(Succeed())
+
+		instance := v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+		}
+
+		Expect(k8sClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      connectionSecretName(instance),
+				Namespace: namespace,
+			},
+			StringData: map[string]string{"password": "foreign"},
+		})).Should(Succeed())
+
+		r := &AuraInstanceReconciler{Client: k8sClient}
+		err := r.writeReplica(ctx, instance, corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: connectionSecretName(instance)},
+		}, namespace)
+
+		Expect(err).To(MatchError(ContainSubstring("is not managed by this instance")))
+	})
+})
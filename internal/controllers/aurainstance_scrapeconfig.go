@@ -0,0 +1,217 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	infrav1beta1 "github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/common/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// scrapeConfigGVK is the ScrapeConfig of the Prometheus operator
+var scrapeConfigGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1alpha1",
+	Kind:    "ScrapeConfig",
+}
+
+func newScrapeConfig() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(scrapeConfigGVK)
+	return obj
+}
+
+// scrapeConfigName returns the name of the ScrapeConfig, defaults to the instance name
+func scrapeConfigName(instance infrav1beta1.AuraInstance) string {
+	if instance.Spec.Outputs == nil || instance.Spec.Outputs.ScrapeConfig == nil {
+		return ""
+	}
+
+	if instance.Spec.Outputs.ScrapeConfig.Name != "" {
+		return instance.Spec.Outputs.ScrapeConfig.Name
+	}
+
+	return instance.Name
+}
+
+// metricsIntegrationURL returns the metrics endpoint of the instance.
+// The metrics endpoint of the project is used if the instance does not expose one.
+func metricsIntegrationURL(ctx context.Context, auraClient *auraclient.ClientWithResponses, instance infrav1beta1.AuraInstance, remote *auraclient.Instance) (string, error) {
+	if remote.Data.MetricsIntegrationUrl != nil && *remote.Data.MetricsIntegrationUrl != "" {
+		return *remote.Data.MetricsIntegrationUrl, nil
+	}
+
+	res, err := auraClient.GetProjectMetricsIntegrationDetailsWithResponse(ctx, instance.Spec.TenantID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get metrics integration details: %w", err)
+	}
+
+	if res.StatusCode() != http.StatusOK {
+		return "", fmt.Errorf("failed to get metrics integration details, request failed with code %d - %s", res.StatusCode(), res.Body)
+	}
+
+	return res.JSON200.Data.Endpoint, nil
+}
+
+// scrapeConfigSpec returns a ScrapeConfig spec scraping the metrics endpoint
+// using the OAuth2 client credentials flow with the Aura API credentials of the instance
+func scrapeConfigSpec(instance infrav1beta1.AuraInstance, metricsURL, tokenURL string) (map[string]interface{}, error) {
+	u, err := url.Parse(metricsURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metrics integration url: %w", err)
+	}
+
+	if u.Host == "" {
+		return nil, errors.New("metrics integration url has no host")
+	}
+
+	clientIDKey, clientSecretKey := credentialKeys(instance)
+	spec := map[string]interface{}{
+		"scheme":      strings.ToUpper(u.Scheme),
+		"metricsPath": u.Path,
+		"staticConfigs": []interface{}{
+			map[string]interface{}{
+				"targets": []interface{}{u.Host},
+				"labels": map[string]interface{}{
+					"namespace": instance.Namespace,
+					"name":      instance.Name,
+				},
+			},
+		},
+		"oauth2": map[string]interface{}{
+			"clientId": map[string]interface{}{
+				"secret": map[string]interface{}{
+					"name": instance.Spec.Secret.Name,
+					"key":  clientIDKey,
+				},
+			},
+			"clientSecret": map[string]interface{}{
+				"name": instance.Spec.Secret.Name,
+				"key":  clientSecretKey,
+			},
+			"tokenUrl": tokenURL,
+		},
+	}
+
+	if interval := instance.Spec.Outputs.ScrapeConfig.Interval; interval != nil {
+		spec["scrapeInterval"] = model.Duration(interval.Duration).String()
+	}
+
+	return spec, nil
+}
+
+// reconcileScrapeConfig publishes the ScrapeConfig of a running instance.
+// Failures are reported in the ScrapeConfigReady condition as they don't affect the instance itself.
+func (r *AuraInstanceReconciler) reconcileScrapeConfig(ctx context.Context, instance infrav1beta1.AuraInstance, auraClient *auraclient.ClientWithResponses, remote *auraclient.Instance, logger logr.Logger) infrav1beta1.AuraInstance {
+	if r.isDryRun(instance) {
+		return instance
+	}
+
+	instance, err := r.writeScrapeConfig(ctx, instance, auraClient, remote)
+	switch {
+	case err != nil:
+		// A ScrapeConfig which doesn't belong to the instance is never taken over, the conflict needs to be resolved manually
+		reason := "ScrapeConfigFailed"
+		if errors.Is(err, errNotControlled) {
+			reason = "ScrapeConfigConflict"
+		}
+
+		msg := fmt.Sprintf("Failed to write ScrapeConfig: %s", err)
+		if conditions.GetMessage(&instance, infrav1beta1.ConditionScrapeConfigReady) != msg {
+			logger.Error(err, "failed to write scrape config")
+			r.Recorder.Event(&instance, "Warning", reason, msg)
+		}
+
+		return infrav1beta1.AuraInstanceScrapeConfigReady(instance, metav1.ConditionFalse, reason, msg)
+	case instance.Status.ScrapeConfig == "":
+		conditions.Delete(&instance, infrav1beta1.ConditionScrapeConfigReady)
+		return instance
+	default:
+		return infrav1beta1.AuraInstanceScrapeConfigReady(instance, metav1.ConditionTrue, "ScrapeConfigWritten", fmt.Sprintf("ScrapeConfig %s is up to date", instance.Status.ScrapeConfig))
+	}
+}
+
+// writeScrapeConfig writes the ScrapeConfig for the Aura metrics of the instance.
+// A ScrapeConfig which has been removed or renamed is deleted.
+func (r *AuraInstanceReconciler) writeScrapeConfig(ctx context.Context, instance infrav1beta1.AuraInstance, auraClient *auraclient.ClientWithResponses, remote *auraclient.Instance) (infrav1beta1.AuraInstance, error) {
+	name := scrapeConfigName(instance)
+
+	if remote.Data.MetricsIntegrationUrl != nil {
+		instance.Status.MetricsIntegrationURL = *remote.Data.MetricsIntegrationUrl
+	}
+
+	if instance.Status.ScrapeConfig != "" && instance.Status.ScrapeConfig != name {
+		if err := r.deleteOwnedObject(ctx, instance, newScrapeConfig(), instance.Status.ScrapeConfig); err != nil {
+			return instance, err
+		}
+
+		instance.Status.ScrapeConfig = ""
+	}
+
+	if name == "" {
+		return instance, nil
+	}
+
+	metricsURL, err := metricsIntegrationURL(ctx, auraClient, instance, remote)
+	if err != nil {
+		return instance, err
+	}
+
+	instance.Status.MetricsIntegrationURL = metricsURL
+	spec, err := scrapeConfigSpec(instance, metricsURL, r.TokenURL)
+	if err != nil {
+		return instance, err
+	}
+
+	scrapeConfig := newScrapeConfig()
+	scrapeConfig.SetName(name)
+	scrapeConfig.SetNamespace(instance.Namespace)
+
+	err = r.writeOwnedObject(ctx, instance, scrapeConfig, func() error {
+		labels := scrapeConfig.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+
+		for k, v := range instance.Spec.Outputs.ScrapeConfig.Labels {
+			labels[k] = v
+		}
+
+		scrapeConfig.SetLabels(labels)
+		scrapeConfig.Object["spec"] = spec
+		return nil
+	})
+
+	if err != nil {
+		return instance, fmt.Errorf("failed to write scrape config %s: %w", name, err)
+	}
+
+	instance.Status.ScrapeConfig = name
+	return instance, nil
+}
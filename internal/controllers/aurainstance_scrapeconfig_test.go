@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("AuraInstance scrape config", func() {
+	It("writes a ScrapeConfig for the Aura metrics", func() {
+		ctx := context.Background()
+		name := fmt.Sprintf("scrape-%s", rand.String(5))
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/tenants/x/metrics-integration" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"data":{"endpoint":"https://customer-metrics-api.neo4j.io/api/v1/x/metrics"}}`))
+		}))
+		defer server.Close()
+
+		auraClient, err := auraclient.NewClientWithResponses(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+
+		instance := &v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1beta1.AuraInstanceSpec{
+				TenantID:      "x",
+				Neo4jVersion:  "5",
+				Tier:          "professional-db",
+				CloudProvider: "gcp",
+				Region:        "europe-west1",
+				Suspend:       true,
+				Secret: v1beta1.SecretReference{
+					Name:        "aura-credentials",
+					ClientIDKey: "id",
+				},
+				Outputs: &v1beta1.Outputs{
+					ScrapeConfig: &v1beta1.ScrapeConfigOutput{
+						Labels:   map[string]string{"release": "prometheus"},
+						Interval: &metav1.Duration{Duration: time.Minute},
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, instance)).Should(Succeed())
+		instance.Status.InstanceID = "abc"
+
+		r := &AuraInstanceReconciler{
+			Client:   k8sClient,
+			Recorder: record.NewFakeRecorder(10),
+			TokenURL: "https://api.neo4j.io/oauth/token",
+		}
+
+		metricsURL := "https://customer-metrics-api.neo4j.io/api/v1/x/abc/metrics"
+		remote := &auraclient.Instance{}
+		remote.Data.MetricsIntegrationUrl = &metricsURL
+
+		By("creating the ScrapeConfig for the instance metrics endpoint")
+		published := r.reconcileScrapeConfig(ctx, *instance, auraClient, remote, ctrl.Log)
+		Expect(conditions.IsTrue(&published, v1beta1.ConditionScrapeConfigReady)).To(BeTrue())
+		Expect(published.Status.ScrapeConfig).To(Equal(name))
+		Expect(published.Status.MetricsIntegrationURL).To(Equal(metricsURL))
+
+		scrapeConfig := newScrapeConfig()
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, scrapeConfig)).Should(Succeed())
+		Expect(scrapeConfig.GetLabels()).To(HaveKeyWithValue("release", "prometheus"))
+		Expect(metav1.IsControlledBy(scrapeConfig, instance)).To(BeTrue())
+
+		Expect(nestedString(scrapeConfig, "spec", "scheme")).To(Equal("HTTPS"))
+		Expect(nestedString(scrapeConfig, "spec", "metricsPath")).To(Equal("/api/v1/x/abc/metrics"))
+		Expect(nestedString(scrapeConfig, "spec", "scrapeInterval")).To(Equal("1m"))
+		Expect(nestedString(scrapeConfig, "spec", "oauth2", "tokenUrl")).To(Equal("https://api.neo4j.io/oauth/token"))
+		Expect(nestedString(scrapeConfig, "spec", "oauth2", "clientId", "secret", "key")).To(Equal("id"))
+		Expect(nestedString(scrapeConfig, "spec", "oauth2", "clientSecret", "key")).To(Equal("clientSecret"))
+
+		staticConfigs, _, _ := unstructured.NestedSlice(scrapeConfig.Object, "spec", "staticConfigs")
+		Expect(staticConfigs).To(HaveLen(1))
+		Expect(staticConfigs[0]).To(HaveKeyWithValue("targets", ConsistOf("customer-metrics-api.neo4j.io")))
+
+		By("falling back to the project metrics endpoint")
+		published = r.reconcileScrapeConfig(ctx, published, auraClient, &auraclient.Instance{}, ctrl.Log)
+		Expect(conditions.IsTrue(&published, v1beta1.ConditionScrapeConfigReady)).To(BeTrue())
+		Expect(published.Status.MetricsIntegrationURL).To(Equal("https://customer-metrics-api.neo4j.io/api/v1/x/metrics"))
+
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, scrapeConfig)).Should(Succeed())
+		Expect(nestedString(scrapeConfig, "spec", "metricsPath")).To(Equal("/api/v1/x/metrics"))
+
+		By("reporting failures in the ScrapeConfigReady condition")
+		invalidURL := "not-a-url"
+		invalid := &auraclient.Instance{}
+		invalid.Data.MetricsIntegrationUrl = &invalidURL
+		published = r.reconcileScrapeConfig(ctx, published, auraClient, invalid, ctrl.Log)
+		Expect(conditions.IsFalse(&published, v1beta1.ConditionScrapeConfigReady)).To(BeTrue())
+		Expect(conditions.GetMessage(&published, v1beta1.ConditionScrapeConfigReady)).To(ContainSubstring("metrics integration url has no host"))
+
+		By("skipping the ScrapeConfig in dry run mode")
+		r.DryRun = true
+		published.Spec.Outputs.ScrapeConfig = nil
+		published = r.reconcileScrapeConfig(ctx, published, auraClient, remote, ctrl.Log)
+		Expect(published.Status.ScrapeConfig).To(Equal(name))
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, scrapeConfig)).Should(Succeed())
+
+		By("deleting the ScrapeConfig once it has been removed")
+		r.DryRun = false
+		published = r.reconcileScrapeConfig(ctx, published, auraClient, remote, ctrl.Log)
+		Expect(published.Status.ScrapeConfig).To(BeEmpty())
+		Expect(conditions.Has(&published, v1beta1.ConditionScrapeConfigReady)).To(BeFalse())
+
+		err = k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, newScrapeConfig())
+		Expect(kerrors.IsNotFound(err)).To(BeTrue())
+
+		By("not taking over a ScrapeConfig which doesn't belong to the instance")
+		foreign := newScrapeConfig()
+		foreign.SetName(name)
+		foreign.SetNamespace("default")
+		foreign.Object["spec"] = map[string]any{"scheme": "HTTP"}
+		Expect(k8sClient.Create(ctx, foreign)).Should(Succeed())
+
+		published.Spec.Outputs.ScrapeConfig = &v1beta1.ScrapeConfigOutput{}
+		published = r.reconcileScrapeConfig(ctx, published, auraClient, remote, ctrl.Log)
+		Expect(conditions.IsFalse(&published, v1beta1.ConditionScrapeConfigReady)).To(BeTrue())
+		Expect(conditions.GetReason(&published, v1beta1.ConditionScrapeConfigReady)).To(Equal("ScrapeConfigConflict"))
+		Expect(published.Status.ScrapeConfig).To(BeEmpty())
+
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, foreign)).Should(Succeed())
+		Expect(nestedString(foreign, "spec", "scheme")).To(Equal("HTTP"))
+		Expect(foreign.GetOwnerReferences()).To(BeEmpty())
+	})
+})
+
+func nestedString(obj *unstructured.Unstructured, fields ...string) string {
+	value, _, err := unstructured.NestedString(obj.Object, fields...)
+	Expect(err).NotTo(HaveOccurred())
+	return value
+}
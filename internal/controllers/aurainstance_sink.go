@@ -0,0 +1,123 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	infrav1beta1 "github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/sink"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// vaultTokenKey is the key of the Vault token within the token secret
+const vaultTokenKey = "token"
+
+// connectionSecretSink returns the sink of the Kubernetes connection secret
+func (r *AuraInstanceReconciler) connectionSecretSink(instance *infrav1beta1.AuraInstance) sink.Sink {
+	return sink.NewKubernetes(r.Client, instance, connectionSecretName(*instance))
+}
+
+// externalSinks returns the external secret stores the connection secret is written to
+func (r *AuraInstanceReconciler) externalSinks(ctx context.Context, instance infrav1beta1.AuraInstance) ([]sink.Sink, error) {
+	if instance.Spec.ConnectionSecret.Sink == nil {
+		return nil, nil
+	}
+
+	var sinks []sink.Sink
+	if vault := instance.Spec.ConnectionSecret.Sink.Vault; vault != nil {
+		var secret corev1.Secret
+		if err := r.Get(ctx, types.NamespacedName{
+			Name:      vault.TokenSecret.Name,
+			Namespace: instance.Namespace,
+		}, &secret); err != nil {
+			return nil, fmt.Errorf("failed to get vault token secret: %w", err)
+		}
+
+		token, ok := secret.Data[vaultTokenKey]
+		if !ok {
+			return nil, fmt.Errorf("vault token secret %s has no key %s", secret.Name, vaultTokenKey)
+		}
+
+		s, err := sink.NewVault(sink.VaultOptions{
+			Address:   vault.Address,
+			Namespace: vault.Namespace,
+			Mount:     vault.Mount,
+			Path:      vault.Path,
+			Token:     string(token),
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		sinks = append(sinks, s)
+	}
+
+	return sinks, nil
+}
+
+// writeSinks writes the connection secret contents to the external secret stores
+func (r *AuraInstanceReconciler) writeSinks(ctx context.Context, instance infrav1beta1.AuraInstance, data map[string][]byte) error {
+	sinks, err := r.externalSinks(ctx, instance)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sinks {
+		if err := s.Write(ctx, data); err != nil {
+			return fmt.Errorf("failed to write connection secret to sink: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// reconcileSinks keeps the external secret stores in sync with the Kubernetes connection secret
+func (r *AuraInstanceReconciler) reconcileSinks(ctx context.Context, instance infrav1beta1.AuraInstance) error {
+	if instance.Spec.ConnectionSecret.Sink == nil {
+		return nil
+	}
+
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{
+		Name:      connectionSecretName(instance),
+		Namespace: instance.Namespace,
+	}, &secret); err != nil {
+		return fmt.Errorf("failed to get connection secret: %w", err)
+	}
+
+	return r.writeSinks(ctx, instance, secret.Data)
+}
+
+// deleteFromSinks removes the connection secret from the external secret stores
+func (r *AuraInstanceReconciler) deleteFromSinks(ctx context.Context, instance infrav1beta1.AuraInstance) error {
+	sinks, err := r.externalSinks(ctx, instance)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sinks {
+		if err := s.Delete(ctx); err != nil {
+			return fmt.Errorf("failed to delete connection secret from sink: %w", err)
+		}
+	}
+
+	return nil
+}
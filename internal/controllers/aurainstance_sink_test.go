@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/sink"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("AuraInstance connection secret sinks", func() {
+	It("writes the connection secret to Vault", func() {
+		ctx := context.Background()
+		name := fmt.Sprintf("sink-%s", rand.String(5))
+
+		var written map[string]string
+		deleted := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Vault-Token") != "root" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == "/v1/kv/data/apps/"+name:
+				w.WriteHeader(http.StatusNotFound)
+			case r.Method == http.MethodPost && r.URL.Path == "/v1/kv/data/apps/"+name:
+				var body struct {
+					Data map[string]string `json:"data"`
+				}
+
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				written = body.Data
+			case r.Method == http.MethodDelete && r.URL.Path == "/v1/kv/metadata/apps/"+name:
+				deleted = true
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		instance := &v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1beta1.AuraInstanceSpec{
+				TenantID:      "x",
+				Neo4jVersion:  "5",
+				Tier:          "free-db",
+				CloudProvider: "gcp",
+				Suspend:       true,
+				ConnectionSecret: v1beta1.ConnectionSecretSpec{
+					Sink: &v1beta1.ConnectionSecretSink{
+						Vault: &v1beta1.VaultSink{
+							Address:     server.URL,
+							Mount:       "kv",
+							Path:        "apps/" + name,
+							TokenSecret: v1beta1.LocalObjectReference{Name: name + "-vault"},
+						},
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, instance)).Should(Succeed())
+
+		r := &AuraInstanceReconciler{
+			Client:   k8sClient,
+			Recorder: record.NewFakeRecorder(10),
+		}
+
+		Expect(r.writeCredentialsBackup(ctx, *instance, "neo4j", "secret", "neo4j+s://abc.databases.neo4j.io")).To(Succeed())
+		Expect(r.reconcileConnectionSecret(ctx, *instance, "neo4j+s://abc.databases.neo4j.io", ctrl.Log)).To(Succeed())
+
+		By("failing without the token secret")
+		Expect(r.reconcileSinks(ctx, *instance)).To(MatchError(ContainSubstring("failed to get vault token secret")))
+
+		Expect(k8sClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name + "-vault",
+				Namespace: "default",
+			},
+			StringData: map[string]string{"token": "root"},
+		})).Should(Succeed())
+
+		By("writing the connection secret")
+		Expect(r.reconcileSinks(ctx, *instance)).To(Succeed())
+		Expect(written).To(HaveKeyWithValue("username", "neo4j"))
+		Expect(written).To(HaveKeyWithValue("password", "secret"))
+		Expect(written).To(HaveKeyWithValue("uri", "neo4j+s://abc.databases.neo4j.io"))
+
+		By("deleting the connection secret")
+		Expect(r.deleteFromSinks(ctx, *instance)).To(Succeed())
+		Expect(deleted).To(BeTrue())
+	})
+
+	It("does not take over secrets which belong to someone else", func() {
+		ctx := context.Background()
+		name := fmt.Sprintf("sink-%s", rand.String(5))
+
+		instance := &v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1beta1.AuraInstanceSpec{
+				TenantID:      "x",
+				Neo4jVersion:  "5",
+				Tier:          "free-db",
+				CloudProvider: "gcp",
+				Suspend:       true,
+			},
+		}
+		Expect(k8sClient.Create(ctx, instance)).Should(Succeed())
+
+		for _, secretName := range []string{connectionSecretName(*instance), credentialsBackupName(*instance)} {
+			Expect(k8sClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      secretName,
+					Namespace: "default",
+				},
+				StringData: map[string]string{"username": "someone-else"},
+			})).Should(Succeed())
+		}
+
+		r := &AuraInstanceReconciler{
+			Client:   k8sClient,
+			Recorder: record.NewFakeRecorder(10),
+		}
+
+		err := r.writeCredentialsBackup(ctx, *instance, "neo4j", "secret", "neo4j+s://abc.databases.neo4j.io")
+		Expect(errors.Is(err, sink.ErrNotControlled)).To(BeTrue())
+
+		err = r.reconcileConnectionSecret(ctx, *instance, "neo4j+s://abc.databases.neo4j.io", ctrl.Log)
+		Expect(errors.Is(err, sink.ErrNotControlled)).To(BeTrue())
+
+		By("deleting only secrets owned by the instance")
+		Expect(r.deleteSecret(ctx, *instance, connectionSecretName(*instance))).To(Succeed())
+
+		for _, secretName := range []string{connectionSecretName(*instance), credentialsBackupName(*instance)} {
+			var secret corev1.Secret
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: "default"}, &secret)).To(Succeed())
+			Expect(secret.OwnerReferences).To(BeEmpty())
+			Expect(string(secret.Data["username"])).To(Equal("someone-else"))
+		}
+	})
+
+	It("removes the finalizer if the vault token secret is gone", func() {
+		ctx := context.Background()
+		name := fmt.Sprintf("sink-%s", rand.String(5))
+
+		instance := &v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       name,
+				Namespace:  "default",
+				Finalizers: []string{v1beta1.Finalizer},
+			},
+			Spec: v1beta1.AuraInstanceSpec{
+				TenantID:      "x",
+				Neo4jVersion:  "5",
+				Tier:          "free-db",
+				CloudProvider: "gcp",
+				Suspend:       true,
+				ConnectionSecret: v1beta1.ConnectionSecretSpec{
+					Sink: &v1beta1.ConnectionSecretSink{
+						Vault: &v1beta1.VaultSink{
+							Address:     "http://127.0.0.1:1",
+							Mount:       "kv",
+							Path:        "apps/" + name,
+							TokenSecret: v1beta1.LocalObjectReference{Name: name + "-vault"},
+						},
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, instance)).Should(Succeed())
+		Expect(k8sClient.Delete(ctx, instance)).Should(Succeed())
+
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(instance), instance)
+			return kerrors.IsNotFound(err)
+		}, time.Second*10, time.Millisecond*200).Should(BeTrue())
+	})
+})
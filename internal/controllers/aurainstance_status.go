@@ -0,0 +1,218 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	infrav1beta1 "github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/sink"
+	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// transientPollInterval is the interval at which instances are polled while Aura is changing their state
+const transientPollInterval = 30 * time.Second
+
+// lifecycleState describes how an Aura instance status is reflected in the conditions
+type lifecycleState struct {
+	// reason of the Ready condition
+	reason  string
+	message string
+
+	// transient states are polled until Aura has finished the transition and mark the instance as reconciling
+	transient bool
+
+	// terminal states can't be recovered from by the controller and need manual intervention
+	terminal bool
+}
+
+// instanceLifecycle maps every Aura instance status to its lifecycle state
+var instanceLifecycle = map[auraclient.InstanceDataStatus]lifecycleState{
+	auraclient.InstanceDataStatusRunning:       {reason: "InstanceRunning", message: "Instance is running"},
+	auraclient.InstanceDataStatusCreating:      {reason: "InstanceCreating", message: "Instance is being created", transient: true},
+	auraclient.InstanceDataStatusLoading:       {reason: "InstanceLoading", message: "Data is being loaded into the instance", transient: true},
+	auraclient.InstanceDataStatusLoadingFailed: {reason: "InstanceLoadingFailed", message: "Loading data into the instance failed, the instance needs to be restored or recreated", terminal: true},
+	auraclient.InstanceDataStatusRestoring:     {reason: "InstanceRestoring", message: "Instance is being restored from a snapshot", transient: true},
+	auraclient.InstanceDataStatusOverwriting:   {reason: "InstanceOverwriting", message: "Instance is being overwritten", transient: true},
+	auraclient.InstanceDataStatusUpdating:      {reason: "InstanceUpdating", message: "Instance is being updated", transient: true},
+	auraclient.InstanceDataStatusPausing:       {reason: "InstancePausing", message: "Instance is being paused", transient: true},
+	auraclient.InstanceDataStatusPaused:        {reason: "InstancePaused", message: "Instance is paused"},
+	auraclient.InstanceDataStatusResuming:      {reason: "InstanceResuming", message: "Instance is being resumed", transient: true},
+	auraclient.InstanceDataStatusSuspending:    {reason: "InstanceSuspending", message: "Instance is being suspended by Aura", transient: true},
+	auraclient.InstanceDataStatusSuspended:     {reason: "InstanceSuspended", message: "Instance has been suspended by Aura and needs to be resumed in the Aura console", terminal: true},
+	auraclient.InstanceDataStatusDestroying:    {reason: "InstanceDestroying", message: "Instance is being destroyed", transient: true},
+}
+
+// operationLifecycle maps the applied Aura operations to the state reported until Aura has picked them up
+var operationLifecycle = map[infrav1beta1.AuraOperation]lifecycleState{
+	infrav1beta1.AuraOperationPatch:   {reason: "UpdatingInstance", message: "Updating Aura instance", transient: true},
+	infrav1beta1.AuraOperationResume:  {reason: "ResumingInstance", message: "Resuming Aura instance", transient: true},
+	infrav1beta1.AuraOperationUpgrade: {reason: "UpgradingInstance", message: "Upgrading Aura instance", transient: true},
+}
+
+// instanceLifecycleState returns the lifecycle state of an Aura instance status.
+// Unknown states are treated as transient.
+func instanceLifecycleState(status auraclient.InstanceDataStatus) lifecycleState {
+	if state, ok := instanceLifecycle[status]; ok {
+		return state
+	}
+
+	return lifecycleState{
+		reason:    "InstanceNotReady",
+		message:   fmt.Sprintf("Instance status: %s", status),
+		transient: true,
+	}
+}
+
+// setLifecycleConditions reflects the lifecycle state in the Ready, Reconciling and Stalled conditions
+func setLifecycleConditions(instance infrav1beta1.AuraInstance, state lifecycleState) infrav1beta1.AuraInstance {
+	if state.terminal {
+		return stall(instance, state.reason, state.message)
+	}
+
+	conditions.Delete(&instance, infrav1beta1.ConditionStalled)
+	if state.transient {
+		instance = infrav1beta1.AuraInstanceReconciling(instance, metav1.ConditionTrue, state.reason, state.message)
+	} else {
+		conditions.Delete(&instance, infrav1beta1.ConditionReconciling)
+	}
+
+	status := metav1.ConditionFalse
+	if state.reason == instanceLifecycle[auraclient.InstanceDataStatusRunning].reason {
+		status = metav1.ConditionTrue
+	}
+
+	return infrav1beta1.AuraInstanceReady(instance, status, state.reason, state.message)
+}
+
+// stall marks the instance as failed until its spec or the Aura instance are changed.
+// The generation is observed as the controller can't make further progress, otherwise it would never be reported as failed.
+func stall(instance infrav1beta1.AuraInstance, reason, message string) infrav1beta1.AuraInstance {
+	conditions.Delete(&instance, infrav1beta1.ConditionReconciling)
+	instance = observeUnappliedGeneration(instance)
+	instance = infrav1beta1.AuraInstanceStalled(instance, metav1.ConditionTrue, reason, message)
+	return infrav1beta1.AuraInstanceReady(instance, metav1.ConditionFalse, reason, message)
+}
+
+// setReconcileResult records the result of a reconciliation following the kstatus conventions.
+// A failed reconciliation is retried and therefore still in progress, its generation is observed nonetheless.
+func setReconcileResult(instance infrav1beta1.AuraInstance, err error) infrav1beta1.AuraInstance {
+	// Secrets which don't belong to the instance are not taken over, the conflict needs to be resolved manually
+	if errors.Is(err, sink.ErrNotControlled) {
+		return stall(instance, "SecretConflict", err.Error())
+	}
+
+	// The same applies to the ConfigMap and Service the connection details are published to
+	if errors.Is(err, errNotControlled) {
+		return stall(instance, "OutputConflict", err.Error())
+	}
+
+	if err != nil {
+		instance = observeUnappliedGeneration(instance)
+		conditions.Delete(&instance, infrav1beta1.ConditionStalled)
+		instance = infrav1beta1.AuraInstanceReconciling(instance, metav1.ConditionTrue, "ProgressingWithRetry", "Reconciliation failed and is retried")
+		return infrav1beta1.AuraInstanceReady(instance, metav1.ConditionFalse, "ReconciliationFailed", err.Error())
+	}
+
+	return instance
+}
+
+// observeGeneration marks the generation as observed and applied once the drift has been compared against its spec
+// and no changes are left to apply. Until then differences to the Aura instance are treated as spec changes
+// which are applied regardless of the drift policy.
+func observeGeneration(instance infrav1beta1.AuraInstance) infrav1beta1.AuraInstance {
+	if len(instance.Status.PlannedChanges) == 0 {
+		instance.Status.ObservedGeneration = instance.GetGeneration()
+		instance.Status.LastAppliedGeneration = instance.GetGeneration()
+	}
+
+	return instance
+}
+
+// observeUnappliedGeneration marks the generation as observed without marking it as applied.
+// The applied generation is recorded first as it otherwise falls back to the observed generation.
+func observeUnappliedGeneration(instance infrav1beta1.AuraInstance) infrav1beta1.AuraInstance {
+	instance.Status.LastAppliedGeneration = appliedGeneration(instance)
+	instance.Status.ObservedGeneration = instance.GetGeneration()
+	return instance
+}
+
+// appliedGeneration returns the last generation applied to the Aura instance.
+// Instances reconciled before the applied generation was recorded fall back to the observed generation.
+func appliedGeneration(instance infrav1beta1.AuraInstance) int64 {
+	if instance.Status.LastAppliedGeneration == 0 {
+		return instance.Status.ObservedGeneration
+	}
+
+	return instance.Status.LastAppliedGeneration
+}
+
+// setInstanceDetails records the details reported by Aura in the instance status
+func setInstanceDetails(instance *infrav1beta1.AuraInstance, remote *auraclient.Instance) {
+	instance.Status.InstanceStatus = string(remote.Data.Status)
+	instance.Status.ConnectionURL = remote.Data.ConnectionUrl
+	instance.Status.Type = string(remote.Data.Type)
+	instance.Status.Memory = remote.Data.Memory
+	instance.Status.Storage = remote.Data.Storage
+	instance.Status.Region = remote.Data.Region
+	instance.Status.CreatedAt = nil
+	instance.Status.GraphNodes = parseCount(remote.Data.GraphNodes)
+	instance.Status.GraphRelationships = parseCount(remote.Data.GraphRelationships)
+	instance.Status.SecondariesCount = nil
+
+	if createdAt, err := time.Parse(time.RFC3339, remote.Data.CreatedAt); err == nil {
+		instance.Status.CreatedAt = &metav1.Time{Time: createdAt}
+	}
+
+	if remote.Data.SecondariesCount != nil {
+		count := int32(*remote.Data.SecondariesCount)
+		instance.Status.SecondariesCount = &count
+	}
+}
+
+// clearInstanceDetails removes the details of an instance which no longer exists in Aura
+func clearInstanceDetails(instance *infrav1beta1.AuraInstance) {
+	instance.Status.InstanceStatus = ""
+	instance.Status.ConnectionURL = ""
+	instance.Status.Type = ""
+	instance.Status.Memory = ""
+	instance.Status.Storage = ""
+	instance.Status.Region = ""
+	instance.Status.CreatedAt = nil
+	instance.Status.GraphNodes = nil
+	instance.Status.GraphRelationships = nil
+	instance.Status.SecondariesCount = nil
+}
+
+// parseCount parses a count Aura reports as string
+func parseCount(count *string) *int64 {
+	if count == nil {
+		return nil
+	}
+
+	n, err := strconv.ParseInt(*count, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	return &n
+}
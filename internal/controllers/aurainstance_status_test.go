@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AuraInstance status", func() {
+	It("records the instance details reported by Aura", func() {
+		nodes := "15"
+		relationships := "invalid"
+		secondaries := float32(2)
+
+		remote := &auraclient.Instance{}
+		remote.Data.Status = auraclient.InstanceDataStatusRunning
+		remote.Data.ConnectionUrl = "neo4j+s://abc.databases.neo4j.io"
+		remote.Data.Type = "professional-db"
+		remote.Data.Memory = "8GB"
+		remote.Data.Storage = "16GB"
+		remote.Data.Region = "europe-west1"
+		remote.Data.CreatedAt = "2025-01-02T03:04:05Z"
+		remote.Data.GraphNodes = &nodes
+		remote.Data.GraphRelationships = &relationships
+		remote.Data.SecondariesCount = &secondaries
+
+		instance := v1beta1.AuraInstance{}
+		setInstanceDetails(&instance, remote)
+
+		Expect(instance.Status.InstanceStatus).To(Equal("running"))
+		Expect(instance.Status.ConnectionURL).To(Equal("neo4j+s://abc.databases.neo4j.io"))
+		Expect(instance.Status.Type).To(Equal("professional-db"))
+		Expect(instance.Status.Memory).To(Equal("8GB"))
+		Expect(instance.Status.Storage).To(Equal("16GB"))
+		Expect(instance.Status.Region).To(Equal("europe-west1"))
+		Expect(instance.Status.CreatedAt.Time).To(BeTemporally("==", time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)))
+		Expect(*instance.Status.GraphNodes).To(Equal(int64(15)))
+		Expect(instance.Status.GraphRelationships).To(BeNil())
+		Expect(*instance.Status.SecondariesCount).To(Equal(int32(2)))
+
+		clearInstanceDetails(&instance)
+		Expect(instance.Status).To(Equal(v1beta1.AuraInstanceStatus{}))
+	})
+})
+
+var _ = Describe("AuraInstance lifecycle", func() {
+	It("maps every Aura instance status", func() {
+		for _, status := range []auraclient.InstanceDataStatus{
+			auraclient.InstanceDataStatusCreating,
+			auraclient.InstanceDataStatusDestroying,
+			auraclient.InstanceDataStatusLoading,
+			auraclient.InstanceDataStatusLoadingFailed,
+			auraclient.InstanceDataStatusOverwriting,
+			auraclient.InstanceDataStatusPaused,
+			auraclient.InstanceDataStatusPausing,
+			auraclient.InstanceDataStatusRestoring,
+			auraclient.InstanceDataStatusResuming,
+			auraclient.InstanceDataStatusRunning,
+			auraclient.InstanceDataStatusSuspended,
+			auraclient.InstanceDataStatusSuspending,
+			auraclient.InstanceDataStatusUpdating,
+		} {
+			Expect(instanceLifecycle).To(HaveKey(status))
+			Expect(instanceLifecycleState(status).reason).NotTo(Equal("InstanceNotReady"))
+		}
+	})
+
+	It("treats unknown states as transient", func() {
+		state := instanceLifecycleState("migrating")
+		Expect(state.reason).To(Equal("InstanceNotReady"))
+		Expect(state.message).To(Equal("Instance status: migrating"))
+		Expect(state.transient).To(BeTrue())
+	})
+
+	It("reflects the lifecycle state in the conditions", func() {
+		instance := setLifecycleConditions(v1beta1.AuraInstance{}, instanceLifecycleState(auraclient.InstanceDataStatusRestoring))
+		Expect(conditions.IsTrue(&instance, v1beta1.ConditionReconciling)).To(BeTrue())
+		Expect(conditions.IsFalse(&instance, v1beta1.ConditionReady)).To(BeTrue())
+		Expect(conditions.GetReason(&instance, v1beta1.ConditionReady)).To(Equal("InstanceRestoring"))
+
+		instance = setLifecycleConditions(instance, instanceLifecycleState(auraclient.InstanceDataStatusLoadingFailed))
+		Expect(conditions.Has(&instance, v1beta1.ConditionReconciling)).To(BeFalse())
+		Expect(conditions.GetReason(&instance, v1beta1.ConditionReady)).To(Equal("InstanceLoadingFailed"))
+		Expect(instanceLifecycleState(auraclient.InstanceDataStatusLoadingFailed).terminal).To(BeTrue())
+
+		instance = setLifecycleConditions(instance, instanceLifecycleState(auraclient.InstanceDataStatusRunning))
+		Expect(conditions.IsTrue(&instance, v1beta1.ConditionReady)).To(BeTrue())
+		Expect(conditions.GetReason(&instance, v1beta1.ConditionReady)).To(Equal("InstanceRunning"))
+	})
+})
@@ -0,0 +1,456 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	infrav1beta1 "github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/bolt"
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/tracing"
+	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	"github.com/fluxcd/pkg/runtime/predicates"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+//+kubebuilder:rbac:groups=neo4j.infra.doodle.com,resources=auraschemamigrations,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=neo4j.infra.doodle.com,resources=auraschemamigrations/status,verbs=get;update;patch
+
+const (
+	readLedgerQuery  = "MATCH (m:__AuraSchemaMigration {migration: $migration}) RETURN m.version AS version, m.checksum AS checksum"
+	writeLedgerQuery = "CREATE (:__AuraSchemaMigration {migration: $migration, version: $version, description: $description, checksum: $checksum, appliedAt: datetime()})"
+)
+
+// migrationKeyPattern matches migrations named V<version>__<description>.cypher
+var migrationKeyPattern = regexp.MustCompile(`^V([0-9]+(?:[._][0-9]+)*)__(.+)\.cypher$`)
+
+// AuraSchemaMigrationReconciler reconciles an AuraSchemaMigration object
+type AuraSchemaMigrationReconciler struct {
+	client.Client
+	Log        logr.Logger
+	Recorder   record.EventRecorder
+	BoltDialer bolt.Dialer
+}
+
+type AuraSchemaMigrationReconcilerOptions struct {
+	MaxConcurrentReconciles int
+}
+
+func (r *AuraSchemaMigrationReconciler) SetupWithManager(mgr ctrl.Manager, opts AuraSchemaMigrationReconcilerOptions) error {
+	if r.BoltDialer == nil {
+		r.BoltDialer = bolt.NewDialer()
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &infrav1beta1.AuraSchemaMigration{}, instanceRefIndexKey,
+		func(o client.Object) []string {
+			sm := o.(*infrav1beta1.AuraSchemaMigration)
+			return []string{
+				fmt.Sprintf("%s/%s", sm.GetNamespace(), sm.Spec.InstanceRef.Name),
+			}
+		},
+	); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &infrav1beta1.AuraSchemaMigration{}, configMapIndexKey,
+		func(o client.Object) []string {
+			sm := o.(*infrav1beta1.AuraSchemaMigration)
+			keys := []string{}
+
+			for _, ref := range sm.Spec.ConfigMaps {
+				keys = append(keys, fmt.Sprintf("%s/%s", sm.GetNamespace(), ref.Name))
+			}
+
+			return keys
+		},
+	); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&infrav1beta1.AuraSchemaMigration{}, builder.WithPredicates(
+			predicate.Or(predicate.GenerationChangedPredicate{}, predicates.ReconcileRequestedPredicate{}),
+		)).
+		WithOptions(controller.Options{MaxConcurrentReconciles: opts.MaxConcurrentReconciles}).
+		Watches(
+			&infrav1beta1.AuraInstance{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForIndexedChange(instanceRefIndexKey)),
+		).
+		Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForIndexedChange(configMapIndexKey)),
+		).
+		Complete(r)
+}
+
+func (r *AuraSchemaMigrationReconciler) requestsForIndexedChange(indexKey string) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		var list infrav1beta1.AuraSchemaMigrationList
+		if err := r.List(ctx, &list, client.MatchingFields{
+			indexKey: objectKey(o).String(),
+		}); err != nil {
+			return nil
+		}
+
+		var reqs []reconcile.Request
+		for _, sm := range list.Items {
+			r.Log.V(1).Info("referenced resource from an AuraSchemaMigration changed detected", "namespace", sm.GetNamespace(), "name", sm.GetName())
+			reqs = append(reqs, reconcile.Request{NamespacedName: objectKey(&sm)})
+		}
+
+		return reqs
+	}
+}
+
+func (r *AuraSchemaMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Log.WithValues("namespace", req.Namespace, "name", req.Name)
+
+	ctx = tracing.ContextWithAttributes(ctx,
+		tracing.AttributeNamespace.String(req.Namespace),
+		tracing.AttributeName.String(req.Name),
+	)
+
+	ctx, span := tracing.Start(ctx, "AuraSchemaMigration.Reconcile")
+	defer span.End()
+
+	sm := infrav1beta1.AuraSchemaMigration{}
+	err := r.Get(ctx, req.NamespacedName, &sm)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		tracing.RecordError(span, err)
+		return reconcile.Result{}, err
+	}
+
+	if sm.Spec.Suspend {
+		logger.Info("aura schema migration is suspended")
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("reconciling aura schema migration")
+	sm, result, err := r.reconcile(ctx, sm, logger)
+	if requestedAt, ok := meta.ReconcileAnnotationValue(sm.GetAnnotations()); ok {
+		sm.Status.SetLastHandledReconcileRequest(requestedAt)
+	}
+
+	sm.Status.ObservedGeneration = sm.GetGeneration()
+
+	if err != nil {
+		logger.Error(err, "reconcile error occurred")
+		sm = infrav1beta1.AuraSchemaMigrationReady(sm, metav1.ConditionFalse, "ReconciliationFailed", err.Error())
+		r.Recorder.Event(&sm, "Warning", "ReconciliationFailed", err.Error())
+		tracing.RecordError(span, err)
+	}
+
+	// Update status after reconciliation
+	if err := r.patchStatus(ctx, &sm); err != nil {
+		logger.Error(err, "unable to update status after reconciliation")
+		return ctrl.Result{Requeue: true}, err
+	}
+
+	return result, err
+}
+
+// migration is a versioned Cypher script
+type migration struct {
+	script
+	version     string
+	description string
+	checksum    string
+}
+
+// ledgerEntry is an applied migration recorded in the database
+type ledgerEntry struct {
+	version  string
+	checksum string
+}
+
+// compareVersions compares two migration versions like 1.2 or 1_2 numerically
+func compareVersions(a, b string) int {
+	split := func(v string) []string {
+		return strings.FieldsFunc(v, func(r rune) bool {
+			return r == '.' || r == '_'
+		})
+	}
+
+	partsA, partsB := split(a), split(b)
+	for i := 0; i < max(len(partsA), len(partsB)); i++ {
+		var numA, numB int
+		if i < len(partsA) {
+			numA, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			numB, _ = strconv.Atoi(partsB[i])
+		}
+
+		if numA != numB {
+			return numA - numB
+		}
+	}
+
+	return 0
+}
+
+// loadMigrations loads the migrations from the ConfigMaps ordered by version
+func loadMigrations(ctx context.Context, c client.Client, namespace string, refs []infrav1beta1.LocalObjectReference) ([]migration, error) {
+	var migrations []migration
+	for _, ref := range refs {
+		var configMap corev1.ConfigMap
+		if err := c.Get(ctx, types.NamespacedName{
+			Name:      ref.Name,
+			Namespace: namespace,
+		}, &configMap); err != nil {
+			return nil, err
+		}
+
+		for key, content := range configMap.Data {
+			match := migrationKeyPattern.FindStringSubmatch(key)
+			if match == nil {
+				return nil, fmt.Errorf("invalid migration %s/%s, expected V<version>__<description>.cypher", ref.Name, key)
+			}
+
+			migrations = append(migrations, migration{
+				script: script{
+					name:    fmt.Sprintf("%s/%s", ref.Name, key),
+					content: content,
+				},
+				version:     match[1],
+				description: strings.ReplaceAll(match[2], "_", " "),
+				checksum:    bolt.Checksum(content),
+			})
+		}
+	}
+
+	slices.SortFunc(migrations, func(a, b migration) int {
+		return compareVersions(a.version, b.version)
+	})
+
+	for i := 1; i < len(migrations); i++ {
+		if compareVersions(migrations[i-1].version, migrations[i].version) == 0 {
+			return nil, fmt.Errorf("duplicate migration version %s: %s and %s", migrations[i].version, migrations[i-1].name, migrations[i].name)
+		}
+	}
+
+	return migrations, nil
+}
+
+// pendingMigrations verifies the applied migrations against the ledger and returns the migrations to apply
+func pendingMigrations(migrations []migration, ledger []ledgerEntry) ([]migration, error) {
+	var latest string
+	for _, entry := range ledger {
+		idx := slices.IndexFunc(migrations, func(m migration) bool {
+			return compareVersions(m.version, entry.version) == 0
+		})
+
+		if idx == -1 {
+			return nil, fmt.Errorf("applied migration %s is missing", entry.version)
+		}
+
+		if migrations[idx].checksum != entry.checksum {
+			return nil, fmt.Errorf("checksum mismatch for applied migration %s (%s)", entry.version, migrations[idx].name)
+		}
+
+		if latest == "" || compareVersions(entry.version, latest) > 0 {
+			latest = entry.version
+		}
+	}
+
+	var pending []migration
+	for _, m := range migrations {
+		if slices.ContainsFunc(ledger, func(entry ledgerEntry) bool {
+			return compareVersions(m.version, entry.version) == 0
+		}) {
+			continue
+		}
+
+		if latest != "" && compareVersions(m.version, latest) < 0 {
+			return nil, fmt.Errorf("migration %s is older than the applied version %s", m.name, latest)
+		}
+
+		pending = append(pending, m)
+	}
+
+	return pending, nil
+}
+
+// migrationID identifies the migrations of an AuraSchemaMigration in the ledger
+func migrationID(m infrav1beta1.AuraSchemaMigration) string {
+	return fmt.Sprintf("%s/%s", m.Namespace, m.Name)
+}
+
+// readLedger returns the migrations recorded in the database
+func readLedger(ctx context.Context, conn bolt.Conn, database, id string) ([]ledgerEntry, error) {
+	rows, err := conn.Run(ctx, database, readLedgerQuery, map[string]any{
+		"migration": id,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration ledger: %w", err)
+	}
+
+	ledger := make([]ledgerEntry, 0, len(rows))
+	for _, row := range rows {
+		version, _ := row["version"].(string)
+		checksum, _ := row["checksum"].(string)
+		ledger = append(ledger, ledgerEntry{version: version, checksum: checksum})
+	}
+
+	return ledger, nil
+}
+
+func (r *AuraSchemaMigrationReconciler) reconcile(ctx context.Context, sm infrav1beta1.AuraSchemaMigration, logger logr.Logger) (infrav1beta1.AuraSchemaMigration, ctrl.Result, error) {
+	var instance infrav1beta1.AuraInstance
+	if err := r.Get(ctx, types.NamespacedName{
+		Name:      sm.Spec.InstanceRef.Name,
+		Namespace: sm.Namespace,
+	}, &instance); err != nil {
+		return sm, reconcile.Result{}, fmt.Errorf("failed to get instance: %w", err)
+	}
+
+	if instance.Status.InstanceStatus != string(auraclient.InstanceDataStatusRunning) {
+		sm = infrav1beta1.AuraSchemaMigrationReady(sm, metav1.ConditionFalse, "InstanceNotReady", fmt.Sprintf("Instance %s is not running", instance.Name))
+		return sm, reconcile.Result{RequeueAfter: time.Second * 30}, nil
+	}
+
+	migrations, err := loadMigrations(ctx, r.Client, sm.Namespace, sm.Spec.ConfigMaps)
+	if err != nil {
+		return sm, reconcile.Result{}, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	// A failed migration blocks all further migrations until it has been changed
+	if failed := sm.Status.FailedMigration; failed != nil {
+		idx := slices.IndexFunc(migrations, func(m migration) bool {
+			return compareVersions(m.version, failed.Version) == 0
+		})
+
+		if idx != -1 && migrations[idx].checksum == failed.Checksum {
+			sm = infrav1beta1.AuraSchemaMigrationReady(sm, metav1.ConditionFalse, "MigrationFailed", fmt.Sprintf("Migration %s failed, change the migration to retry", failed.Version))
+			return sm, reconcile.Result{}, nil
+		}
+
+		logger.Info("failed migration has been changed, retrying", "version", failed.Version)
+		sm.Status.FailedMigration = nil
+	}
+
+	conn, _, err := dialInstance(ctx, r.Client, r.BoltDialer, instance)
+	if errors.Is(err, errCredentialsUnavailable) {
+		sm = infrav1beta1.AuraSchemaMigrationReady(sm, metav1.ConditionFalse, "CredentialsUnavailable", err.Error())
+		return sm, reconcile.Result{}, nil
+	}
+
+	if err != nil {
+		return sm, reconcile.Result{}, err
+	}
+
+	defer func() {
+		_ = conn.Close(ctx)
+	}()
+
+	ledger, err := readLedger(ctx, conn, sm.Spec.Database, migrationID(sm))
+	if err != nil {
+		return sm, reconcile.Result{}, err
+	}
+
+	pending, err := pendingMigrations(migrations, ledger)
+	if err != nil {
+		sm = infrav1beta1.AuraSchemaMigrationFailed(sm, metav1.ConditionTrue, "ValidationFailed", err.Error())
+		sm = infrav1beta1.AuraSchemaMigrationReady(sm, metav1.ConditionFalse, "ValidationFailed", err.Error())
+		return sm, reconcile.Result{}, nil
+	}
+
+	for _, m := range pending {
+		logger.Info("applying migration", "version", m.version, "migration", m.name)
+
+		var statements []statement
+		for _, query := range bolt.SplitStatements(m.content) {
+			statements = append(statements, statement{query: query})
+		}
+
+		if err := runStatements(ctx, conn, sm.Spec.Database, statements); err != nil {
+			sm.Status.FailedMigration = &infrav1beta1.FailedMigration{
+				Version:  m.version,
+				Checksum: m.checksum,
+				Message:  err.Error(),
+			}
+
+			msg := fmt.Sprintf("Migration %s failed: %s", m.version, err)
+			sm = infrav1beta1.AuraSchemaMigrationFailed(sm, metav1.ConditionTrue, "MigrationFailed", msg)
+			sm = infrav1beta1.AuraSchemaMigrationReady(sm, metav1.ConditionFalse, "MigrationFailed", msg)
+			r.Recorder.Event(&sm, "Warning", "MigrationFailed", msg)
+			return sm, reconcile.Result{}, nil
+		}
+
+		if _, err := conn.Run(ctx, sm.Spec.Database, writeLedgerQuery, map[string]any{
+			"migration":   migrationID(sm),
+			"version":     m.version,
+			"description": m.description,
+			"checksum":    m.checksum,
+		}); err != nil {
+			return sm, reconcile.Result{}, fmt.Errorf("failed to record migration %s in the ledger: %w", m.version, err)
+		}
+
+		sm.Status.CurrentVersion = m.version
+		r.Recorder.Event(&sm, "Normal", "MigrationApplied", fmt.Sprintf("Applied migration %s", m.name))
+	}
+
+	if sm.Status.CurrentVersion == "" && len(ledger) > 0 {
+		sm.Status.CurrentVersion = slices.MaxFunc(ledger, func(a, b ledgerEntry) int {
+			return compareVersions(a.version, b.version)
+		}).version
+	}
+
+	conditions.Delete(&sm, infrav1beta1.ConditionMigrationFailed)
+	msg := "No migrations to apply"
+	if sm.Status.CurrentVersion != "" {
+		msg = fmt.Sprintf("Migrated to version %s", sm.Status.CurrentVersion)
+	}
+
+	sm = infrav1beta1.AuraSchemaMigrationReady(sm, metav1.ConditionTrue, "MigrationsApplied", msg)
+
+	return sm, reconcile.Result{}, nil
+}
+
+func (r *AuraSchemaMigrationReconciler) patchStatus(ctx context.Context, sm *infrav1beta1.AuraSchemaMigration) error {
+	key := client.ObjectKeyFromObject(sm)
+	latest := &infrav1beta1.AuraSchemaMigration{}
+	if err := r.Get(ctx, key, latest); err != nil {
+		return err
+	}
+
+	return r.Status().Patch(ctx, sm, client.MergeFrom(latest))
+}
@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("AuraSchemaMigration versions", func() {
+	It("compares versions numerically", func() {
+		Expect(compareVersions("1", "2")).To(BeNumerically("<", 0))
+		Expect(compareVersions("10", "9")).To(BeNumerically(">", 0))
+		Expect(compareVersions("1.1", "1_1")).To(BeZero())
+		Expect(compareVersions("1.0", "1")).To(BeZero())
+		Expect(compareVersions("1.2", "1.10")).To(BeNumerically("<", 0))
+	})
+
+	newMigration := func(version, content string) migration {
+		return migration{
+			script:   script{name: fmt.Sprintf("migrations/V%s__test.cypher", version), content: content},
+			version:  version,
+			checksum: content,
+		}
+	}
+
+	migrations := []migration{
+		newMigration("1", "a"),
+		newMigration("2", "b"),
+		newMigration("3", "c"),
+	}
+
+	It("returns the migrations which have not been applied", func() {
+		pending, err := pendingMigrations(migrations, []ledgerEntry{
+			{version: "1", checksum: "a"},
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pending).To(Equal(migrations[1:]))
+	})
+
+	It("fails if an applied migration has been changed", func() {
+		_, err := pendingMigrations(migrations, []ledgerEntry{
+			{version: "1", checksum: "changed"},
+		})
+
+		Expect(err).To(MatchError(ContainSubstring("checksum mismatch for applied migration 1")))
+	})
+
+	It("fails if an applied migration is missing", func() {
+		_, err := pendingMigrations(migrations, []ledgerEntry{
+			{version: "0", checksum: "a"},
+		})
+
+		Expect(err).To(MatchError("applied migration 0 is missing"))
+	})
+
+	It("fails if a migration is older than the applied version", func() {
+		_, err := pendingMigrations(migrations, []ledgerEntry{
+			{version: "1", checksum: "a"},
+			{version: "3", checksum: "c"},
+		})
+
+		Expect(err).To(MatchError(ContainSubstring("is older than the applied version 3")))
+	})
+})
+
+var _ = Describe("AuraSchemaMigration reconciliation", func() {
+	It("applies migrations in order and blocks after a failure", func() {
+		ctx := context.Background()
+		name := fmt.Sprintf("migration-%s", rand.String(5))
+
+		By("creating a running instance")
+		instance := &v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1beta1.AuraInstanceSpec{
+				TenantID:      "x",
+				Neo4jVersion:  "5",
+				Tier:          "free-db",
+				CloudProvider: "gcp",
+				Suspend:       true,
+			},
+		}
+		Expect(k8sClient.Create(ctx, instance)).Should(Succeed())
+		instance.Status.InstanceStatus = "running"
+		Expect(k8sClient.Status().Update(ctx, instance)).Should(Succeed())
+
+		Expect(k8sClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      credentialsBackupName(*instance),
+				Namespace: "default",
+			},
+			StringData: map[string]string{
+				"username":      "neo4j",
+				"password":      "secret",
+				"connectionURL": "neo4j+s://abc.databases.neo4j.io",
+			},
+		})).Should(Succeed())
+
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Data: map[string]string{
+				"V10__backfill.cypher":    "MATCH (u:User) SET u.active = true",
+				"V2__constraints.cypher":  "CREATE CONSTRAINT user_id IF NOT EXISTS FOR (u:User) REQUIRE u.id IS UNIQUE",
+				"V1.1__index_name.cypher": "CREATE INDEX user_name IF NOT EXISTS FOR (u:User) ON (u.name)",
+			},
+		}
+		Expect(k8sClient.Create(ctx, configMap)).Should(Succeed())
+
+		sm := v1beta1.AuraSchemaMigration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1beta1.AuraSchemaMigrationSpec{
+				InstanceRef: v1beta1.LocalObjectReference{Name: name},
+				ConfigMaps:  []v1beta1.LocalObjectReference{{Name: name}},
+			},
+		}
+
+		dialer := &fakeDialer{password: "secret"}
+		r := &AuraSchemaMigrationReconciler{
+			Client:     k8sClient,
+			Recorder:   record.NewFakeRecorder(10),
+			BoltDialer: dialer,
+		}
+
+		By("applying the migrations")
+		sm, _, err := r.reconcile(ctx, sm, ctrl.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conditions.IsTrue(&sm, v1beta1.ConditionReady)).To(BeTrue())
+		Expect(sm.Status.CurrentVersion).To(Equal("10"))
+		Expect(dialer.queries).To(Equal([]string{
+			readLedgerQuery,
+			"CREATE INDEX user_name IF NOT EXISTS FOR (u:User) ON (u.name)",
+			writeLedgerQuery,
+			"CREATE CONSTRAINT user_id IF NOT EXISTS FOR (u:User) REQUIRE u.id IS UNIQUE",
+			writeLedgerQuery,
+			"MATCH (u:User) SET u.active = true",
+			writeLedgerQuery,
+		}))
+		Expect(dialer.ledger).To(HaveLen(3))
+		Expect(dialer.ledger[0]).To(HaveKeyWithValue("description", "index name"))
+
+		By("failing a new migration")
+		failing := "MATCH (u:User) SET u.name = toLower(u.nam)"
+		dialer.queries = nil
+		dialer.queryErrs = map[string]error{failing: errors.New("syntax error")}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, configMap)).Should(Succeed())
+		configMap.Data["V11__lowercase.cypher"] = failing
+		Expect(k8sClient.Update(ctx, configMap)).Should(Succeed())
+
+		sm, _, err = r.reconcile(ctx, sm, ctrl.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conditions.IsTrue(&sm, v1beta1.ConditionMigrationFailed)).To(BeTrue())
+		Expect(conditions.IsFalse(&sm, v1beta1.ConditionReady)).To(BeTrue())
+		Expect(sm.Status.FailedMigration.Version).To(Equal("11"))
+		Expect(sm.Status.CurrentVersion).To(Equal("10"))
+
+		By("blocking until the failed migration is changed")
+		dialer.queries = nil
+		sm, _, err = r.reconcile(ctx, sm, ctrl.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dialer.queries).To(BeEmpty())
+		Expect(conditions.GetReason(&sm, v1beta1.ConditionReady)).To(Equal("MigrationFailed"))
+
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, configMap)).Should(Succeed())
+		configMap.Data["V11__lowercase.cypher"] = "MATCH (u:User) SET u.name = toLower(u.name)"
+		Expect(k8sClient.Update(ctx, configMap)).Should(Succeed())
+
+		sm, _, err = r.reconcile(ctx, sm, ctrl.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sm.Status.FailedMigration).To(BeNil())
+		Expect(sm.Status.CurrentVersion).To(Equal("11"))
+		Expect(conditions.Has(&sm, v1beta1.ConditionMigrationFailed)).To(BeFalse())
+
+		By("detecting changes of applied migrations")
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, configMap)).Should(Succeed())
+		configMap.Data["V2__constraints.cypher"] = "CREATE CONSTRAINT user_email IF NOT EXISTS FOR (u:User) REQUIRE u.email IS UNIQUE"
+		Expect(k8sClient.Update(ctx, configMap)).Should(Succeed())
+
+		sm, _, err = r.reconcile(ctx, sm, ctrl.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conditions.GetReason(&sm, v1beta1.ConditionMigrationFailed)).To(Equal("ValidationFailed"))
+	})
+})
@@ -0,0 +1,114 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	infrav1beta1 "github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/bolt"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// errCredentialsUnavailable is returned if the admin credentials of an instance are not backed up
+var errCredentialsUnavailable = errors.New("admin credentials are not available, the credentials backup is missing")
+
+// instanceCredentials returns the backed up admin credentials and connection url of the instance
+func instanceCredentials(ctx context.Context, c client.Client, instance infrav1beta1.AuraInstance) (string, bolt.Auth, error) {
+	var backup corev1.Secret
+	err := c.Get(ctx, types.NamespacedName{
+		Name:      credentialsBackupName(instance),
+		Namespace: instance.Namespace,
+	}, &backup)
+
+	if kerrors.IsNotFound(err) {
+		return "", bolt.Auth{}, errCredentialsUnavailable
+	}
+
+	if err != nil {
+		return "", bolt.Auth{}, fmt.Errorf("failed to get credentials backup: %w", err)
+	}
+
+	return string(backup.Data[credentialsConnectionURLKey]), bolt.Auth{
+		Username: string(backup.Data[credentialsUsernameKey]),
+		Password: string(backup.Data[credentialsPasswordKey]),
+	}, nil
+}
+
+// connectionSecretCredentials returns the credentials and connection url workloads use from the connection secret.
+// The backed up admin credentials are used if the connection secret is templated without plain credential entries.
+func connectionSecretCredentials(ctx context.Context, c client.Client, instance infrav1beta1.AuraInstance) (string, bolt.Auth, error) {
+	usernameKey, passwordKey, connectionURLKey := connectionSecretCredentialKeys(instance)
+	if usernameKey == "" || passwordKey == "" {
+		return instanceCredentials(ctx, c, instance)
+	}
+
+	var secret corev1.Secret
+	if err := c.Get(ctx, types.NamespacedName{
+		Name:      connectionSecretName(instance),
+		Namespace: instance.Namespace,
+	}, &secret); err != nil {
+		return "", bolt.Auth{}, fmt.Errorf("failed to get connection secret: %w", err)
+	}
+
+	connectionURL := instance.Status.ConnectionURL
+	if connectionURLKey != "" {
+		connectionURL = string(secret.Data[connectionURLKey])
+	}
+
+	return connectionURL, bolt.Auth{
+		Username: string(secret.Data[usernameKey]),
+		Password: string(secret.Data[passwordKey]),
+	}, nil
+}
+
+// dialInstance connects to the instance using the backed up admin credentials
+func dialInstance(ctx context.Context, c client.Client, dialer bolt.Dialer, instance infrav1beta1.AuraInstance) (bolt.Conn, string, error) {
+	url, auth, err := instanceCredentials(ctx, c, instance)
+	if err != nil {
+		return nil, "", err
+	}
+
+	conn, err := dialer.Dial(ctx, url, auth)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to connect to instance: %w", err)
+	}
+
+	return conn, url, nil
+}
+
+// statement is a Cypher query including its parameters
+type statement struct {
+	query  string
+	params map[string]any
+}
+
+// runStatements executes the statements in order
+func runStatements(ctx context.Context, conn bolt.Conn, database string, statements []statement) error {
+	for _, stmt := range statements {
+		if _, err := conn.Run(ctx, database, stmt.query, stmt.params); err != nil {
+			return fmt.Errorf("failed to execute %q: %w", stmt.query, err)
+		}
+	}
+
+	return nil
+}
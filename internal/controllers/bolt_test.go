@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/bolt"
+)
+
+// fakeDialer simulates a Neo4j instance with a single user
+type fakeDialer struct {
+	password string
+	// rejectPasswords fails authentication for the given passwords even if they are valid
+	rejectPasswords []string
+	runErr          error
+	// queryErrs fails the given queries
+	queryErrs map[string]error
+	queries   []string
+	ledger    []map[string]any
+}
+
+func (d *fakeDialer) Dial(ctx context.Context, url string, auth bolt.Auth) (bolt.Conn, error) {
+	for _, password := range d.rejectPasswords {
+		if password == auth.Password {
+			return nil, errors.New("unauthorized")
+		}
+	}
+
+	if auth.Password != d.password {
+		return nil, errors.New("unauthorized")
+	}
+
+	return &fakeConn{dialer: d}, nil
+}
+
+type fakeConn struct {
+	dialer *fakeDialer
+}
+
+func (c *fakeConn) Run(ctx context.Context, database, query string, params map[string]any) ([]map[string]any, error) {
+	c.dialer.queries = append(c.dialer.queries, query)
+	if c.dialer.runErr != nil {
+		return nil, c.dialer.runErr
+	}
+
+	if err, ok := c.dialer.queryErrs[query]; ok {
+		return nil, err
+	}
+
+	switch query {
+	case readLedgerQuery:
+		return c.dialer.ledger, nil
+	case writeLedgerQuery:
+		c.dialer.ledger = append(c.dialer.ledger, params)
+	case alterPasswordQuery:
+		if params["old"] != c.dialer.password {
+			return nil, errors.New("invalid current password")
+		}
+
+		c.dialer.password = params["new"].(string)
+	}
+
+	return nil, nil
+}
+
+func (c *fakeConn) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	"github.com/fluxcd/pkg/apis/meta"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Reconcile requests", func() {
+	const (
+		timeout  = time.Second * 4
+		interval = time.Millisecond * 200
+	)
+
+	requestReconcile := func(ctx context.Context, obj client.Object, requestedAt string) {
+		patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+		obj.SetAnnotations(map[string]string{meta.ReconcileRequestAnnotation: requestedAt})
+		Expect(k8sClient.Patch(ctx, obj, patch)).To(Succeed())
+	}
+
+	It("handles reconcile requests of an AuraInstance", func() {
+		ctx := context.Background()
+		name := fmt.Sprintf("request-%s", rand.String(5))
+
+		instance := &v1beta1.AuraInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1beta1.AuraInstanceSpec{
+				TenantID:      "x",
+				Neo4jVersion:  "5",
+				Tier:          "free-db",
+				CloudProvider: "gcp",
+				Secret: v1beta1.SecretReference{
+					Name: "does-not-exist",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, instance)).To(Succeed())
+
+		for _, requestedAt := range []string{"2025-01-01T00:00:00Z", "2025-01-01T00:01:00Z"} {
+			requestReconcile(ctx, instance, requestedAt)
+
+			Eventually(func() string {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, instance)).To(Succeed())
+				return instance.Status.LastHandledReconcileAt
+			}, timeout, interval).Should(Equal(requestedAt))
+		}
+	})
+
+	It("handles reconcile requests of an AuraDatabaseUser", func() {
+		ctx := context.Background()
+		name := fmt.Sprintf("request-%s", rand.String(5))
+
+		user := &v1beta1.AuraDatabaseUser{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1beta1.AuraDatabaseUserSpec{
+				InstanceRef: v1beta1.LocalObjectReference{
+					Name: "does-not-exist",
+				},
+				Roles: []string{"reader"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, user)).To(Succeed())
+
+		Eventually(func() int64 {
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, user)).To(Succeed())
+			return user.Status.ObservedGeneration
+		}, timeout, interval).Should(Equal(int64(1)))
+
+		requestReconcile(ctx, user, "2025-01-01T00:00:00Z")
+
+		Eventually(func() string {
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, user)).To(Succeed())
+			return user.Status.LastHandledReconcileAt
+		}, timeout, interval).Should(Equal("2025-01-01T00:00:00Z"))
+	})
+})
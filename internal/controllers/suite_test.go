@@ -18,15 +18,14 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"io"
-	"net/http"
 	"path/filepath"
-	"strings"
 	"testing"
+	"time"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	"github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/fake"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -45,9 +44,7 @@ var (
 	testEnv    *envtest.Environment
 	ctx        context.Context
 	cancel     context.CancelFunc
-	httpClient = &http.Client{
-		Transport: &mockTransport{},
-	}
+	auraServer *fake.Server
 )
 
 func TestAPIs(t *testing.T) {
@@ -63,7 +60,10 @@ var _ = BeforeSuite(func() {
 
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
-		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "base", "crd", "bases")},
+		CRDDirectoryPaths: []string{
+			filepath.Join("..", "..", "config", "base", "crd", "bases"),
+			filepath.Join("testdata", "crds"),
+		},
 		ErrorIfCRDPathMissing: true,
 	}
 
@@ -82,20 +82,39 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	Expect(k8sClient).NotTo(BeNil())
 
+	auraServer = fake.NewServer(fake.WithTransitionDelay(time.Second))
+
 	k8sManager, err := ctrl.NewManager(cfg, ctrl.Options{
 		Scheme: scheme.Scheme,
 	})
 	Expect(err).ToNot(HaveOccurred())
 
 	err = (&AuraInstanceReconciler{
-		HTTPClient: httpClient,
-		TokenURL:   "https://token-endpoint",
+		HTTPClient: auraServer.Client(),
+		BaseURL:    auraServer.BaseURL(),
+		TokenURL:   auraServer.TokenURL(),
 		Client:     k8sManager.GetClient(),
 		Log:        ctrl.Log.WithName("controllers").WithName("AuraInstane"),
 		Recorder:   k8sManager.GetEventRecorderFor("AuraInstane"),
+		// Instances of the fake Aura API don't accept Bolt connections
+		BoltDialer: &fakeDialer{},
 	}).SetupWithManager(k8sManager, AuraInstanceReconcilerOptions{})
 	Expect(err).ToNot(HaveOccurred())
 
+	err = (&AuraDatabaseUserReconciler{
+		Client:   k8sManager.GetClient(),
+		Log:      ctrl.Log.WithName("controllers").WithName("AuraDatabaseUser"),
+		Recorder: k8sManager.GetEventRecorderFor("AuraDatabaseUser"),
+	}).SetupWithManager(k8sManager, AuraDatabaseUserReconcilerOptions{})
+	Expect(err).ToNot(HaveOccurred())
+
+	err = (&AuraSchemaMigrationReconciler{
+		Client:   k8sManager.GetClient(),
+		Log:      ctrl.Log.WithName("controllers").WithName("AuraSchemaMigration"),
+		Recorder: k8sManager.GetEventRecorderFor("AuraSchemaMigration"),
+	}).SetupWithManager(k8sManager, AuraSchemaMigrationReconcilerOptions{})
+	Expect(err).ToNot(HaveOccurred())
+
 	go func() {
 		defer GinkgoRecover()
 		err = k8sManager.Start(ctx)
@@ -104,27 +123,28 @@ var _ = BeforeSuite(func() {
 })
 
 var _ = AfterSuite(func() {
-	cancel()
+	// The BeforeSuite might have failed before everything was set up
+	if cancel != nil {
+		cancel()
+	}
+
+	if auraServer != nil {
+		auraServer.Close()
+	}
+
 	By("tearing down the test environment")
-	err := testEnv.Stop()
-	Expect(err).NotTo(HaveOccurred())
+	if testEnv != nil {
+		err := testEnv.Stop()
+		Expect(err).NotTo(HaveOccurred())
+	}
 })
 
-type mockTransport struct {
-}
-
-func (m *mockTransport) RoundTrip(r *http.Request) (*http.Response, error) {
-	if r.Host == "token-endpoint" {
-		return &http.Response{
-			StatusCode: 200,
-			Body:       io.NopCloser(strings.NewReader(`{"access_token": "token", "expires_in": 3600}`)),
-		}, nil
+func needsObservedGeneration(expected, current int64) error {
+	if expected != current {
+		return fmt.Errorf("observed generation does not match expected generation %d, current generation=%d", expected, current)
 	}
 
-	return &http.Response{
-		StatusCode: 500,
-		Body:       io.NopCloser(strings.NewReader(`{"error":"error"}`)),
-	}, nil
+	return nil
 }
 
 func needsExactConditions(expected []metav1.Condition, current []metav1.Condition) error {
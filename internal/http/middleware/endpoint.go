@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// otherEndpoint is used for requests which don't match any endpoint template
+const otherEndpoint = "other"
+
+type endpoint struct {
+	template string
+	pattern  *regexp.Regexp
+}
+
+// endpoints maps request paths to endpoint templates
+type endpoints struct {
+	tokenURL  string
+	endpoints []endpoint
+}
+
+func newEndpoints(tokenURL string, templates []string) *endpoints {
+	e := &endpoints{
+		tokenURL: tokenURL,
+	}
+
+	for _, template := range templates {
+		e.endpoints = append(e.endpoints, endpoint{
+			template: template,
+			pattern:  templatePattern(template),
+		})
+	}
+
+	return e
+}
+
+var templateParam = regexp.MustCompile(`\\\{[^/]+\\\}`)
+
+// templatePattern converts a path template into a regular expression matching the end of a path
+func templatePattern(template string) *regexp.Regexp {
+	pattern := templateParam.ReplaceAllString(regexp.QuoteMeta(template), "[^/]+")
+	return regexp.MustCompile(fmt.Sprintf("%s/?$", pattern))
+}
+
+// endpoint returns the first template matching the end of the request path
+func (e *endpoints) endpoint(path string) string {
+	for _, endpoint := range e.endpoints {
+		if endpoint.pattern.MatchString(path) {
+			return endpoint.template
+		}
+	}
+
+	return otherEndpoint
+}
+
+// isTokenRequest reports whether the request is sent to the OAuth2 token endpoint
+func (e *endpoints) isTokenRequest(req *http.Request) bool {
+	return e.tokenURL != "" && strings.TrimSuffix(req.URL.String(), "/") == strings.TrimSuffix(e.tokenURL, "/")
+}
@@ -1,32 +1,235 @@
 package middleware
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/go-logr/logr"
 )
 
+// RequestIDHeader is the header carrying the id of a request sent to the Aura API
+const RequestIDHeader = "X-Request-Id"
+
+// DefaultMaxBodySize is the number of bytes of a body which are logged if not configured otherwise
+const DefaultMaxBodySize = 4096
+
+const redacted = "[REDACTED]"
+
+// redactedFields are the body fields whose values are never logged
+var redactedFields = []string{"password", "access_token", "refresh_token", "id_token", "client_secret"}
+
+// redactedHeaders are the headers whose values are never logged
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
 type log struct {
-	logger logr.Logger
-	next   http.RoundTripper
+	logger      logr.Logger
+	next        http.RoundTripper
+	logBodies   bool
+	maxBodySize int
 }
 
-func NewLogger(logger logr.Logger, next http.RoundTripper) *log {
-	return &log{
-		logger: logger,
-		next:   next,
+// LogOption configures the logging middleware
+type LogOption func(*log)
+
+// WithBodies logs the headers and bodies of requests and responses with secrets redacted.
+// Bodies are truncated to maxBodySize bytes.
+func WithBodies(maxBodySize int) LogOption {
+	return func(l *log) {
+		l.logBodies = true
+		l.maxBodySize = maxBodySize
 	}
 }
 
+func NewLogger(logger logr.Logger, next http.RoundTripper, opts ...LogOption) *log {
+	l := &log{
+		logger:      logger,
+		next:        next,
+		maxBodySize: DefaultMaxBodySize,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
 func (p *log) RoundTrip(req *http.Request) (*http.Response, error) {
-	p.logger.V(1).Info("http request sent", "method", req.Method, "uri", req.URL.String())
-	res, err := p.next.RoundTrip(req)
+	// The request is cloned as a RoundTripper must not modify the request it was given
+	req = req.Clone(req.Context())
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
 
-	if err != nil {
-		p.logger.V(1).Error(err, "http request failed", "method", req.Method, "uri", req.URL.String())
+	requestID := req.Header.Get(RequestIDHeader)
+	if requestID == "" {
+		requestID = newRequestID()
+		req.Header.Set(RequestIDHeader, requestID)
+	}
+
+	logger := p.logger.WithValues("requestID", requestID, "method", req.Method, "uri", req.URL.String())
+	if !p.logBodies {
+		logger.V(1).Info("http request sent")
 	} else {
-		p.logger.V(1).Info("http response received", "method", req.Method, "uri", req.URL.String(), "status", res.StatusCode)
+		body, err := p.readBody(&req.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		logger.V(1).Info("http request sent", "headers", redactHeaders(req.Header), "body", p.formatBody(req.Header, body))
+	}
+
+	res, err := p.next.RoundTrip(req)
+	if err != nil {
+		logger.V(1).Error(err, "http request failed")
+		return res, err
+	}
+
+	if !p.logBodies {
+		logger.V(1).Info("http response received", "status", res.StatusCode)
+		return res, err
+	}
+
+	body, readErr := p.readBody(&res.Body)
+	if readErr != nil {
+		// A RoundTripper must not return a response together with an error
+		logger.V(1).Error(readErr, "failed to read http response body")
+		_ = res.Body.Close()
+		return nil, readErr
 	}
 
+	logger.V(1).Info("http response received", "status", res.StatusCode, "headers", redactHeaders(res.Header), "body", p.formatBody(res.Header, body))
 	return res, err
 }
+
+// readBody reads the body and replaces it with a reader over the read bytes
+func (p *log) readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+
+	b, err := io.ReadAll(*body)
+	_ = (*body).Close()
+	*body = io.NopCloser(bytes.NewReader(b))
+	return b, err
+}
+
+// formatBody redacts secrets from the body and truncates it to the maximum body size
+func (p *log) formatBody(header http.Header, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	s := redactBody(header.Get("Content-Type"), body)
+	if len(s) > p.maxBodySize {
+		// Multi-byte characters are not cut in half
+		n := p.maxBodySize
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+
+		return s[:n] + "...(truncated)"
+	}
+
+	return s
+}
+
+func redactBody(contentType string, body []byte) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/x-www-form-urlencoded" {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return redacted
+		}
+
+		for _, field := range redactedFields {
+			if values.Has(field) {
+				values.Set(field, redacted)
+			}
+		}
+
+		return values.Encode()
+	}
+
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		// Bodies which can not be inspected might contain secrets
+		if containsRedactedField(string(body)) {
+			return redacted
+		}
+
+		return string(body)
+	}
+
+	b, err := json.Marshal(redactJSON(v))
+	if err != nil {
+		return redacted
+	}
+
+	return string(b)
+}
+
+func redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if isRedactedField(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactJSON(value)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = redactJSON(value)
+		}
+	}
+
+	return v
+}
+
+func isRedactedField(key string) bool {
+	for _, field := range redactedFields {
+		if strings.EqualFold(key, field) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsRedactedField(body string) bool {
+	body = strings.ToLower(body)
+	for _, field := range redactedFields {
+		if strings.Contains(body, field) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func redactHeaders(header http.Header) http.Header {
+	header = header.Clone()
+	for _, name := range redactedHeaders {
+		if header.Get(name) != "" {
+			header.Set(name, redacted)
+		}
+	}
+
+	return header
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -1,14 +1,16 @@
-//go:build unit
-
 package middleware
 
 import (
 	"errors"
+	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
 	"github.com/tj/assert"
 )
 
@@ -36,3 +38,156 @@ func TestLog(t *testing.T) {
 	assert.Error(t, err)
 
 }
+
+func newTestLogger(lines *[]string) logr.Logger {
+	return funcr.New(func(prefix, args string) {
+		*lines = append(*lines, args)
+	}, funcr.Options{Verbosity: 1})
+}
+
+func TestLogRequestID(t *testing.T) {
+	var lines []string
+	mock := NewMock(&http.Response{StatusCode: 200}, nil)
+
+	req := &http.Request{Method: http.MethodGet, URL: mustParseURL(t, "https://api.neo4j.io/v1/instances")}
+	_, err := NewLogger(newTestLogger(&lines), mock).RoundTrip(req)
+	assert.NoError(t, err)
+
+	requestID := mock.req.Header.Get(RequestIDHeader)
+	assert.Len(t, requestID, 16)
+	assert.Empty(t, req.Header.Get(RequestIDHeader))
+	assert.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.Contains(t, line, `"requestID"="`+requestID+`"`)
+		assert.NotContains(t, line, `"body"`)
+	}
+}
+
+func TestLogBodies(t *testing.T) {
+	var lines []string
+	mock := NewMock(&http.Response{
+		StatusCode: 202,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"data":{"id":"abc","username":"neo4j","password":"secret-password"}}`)),
+	}, nil)
+
+	req := &http.Request{
+		Method: http.MethodPost,
+		URL:    mustParseURL(t, "https://api.neo4j.io/v1/instances"),
+		Header: http.Header{"Authorization": []string{"Bearer secret-token"}},
+		Body:   io.NopCloser(strings.NewReader(`{"name":"test"}`)),
+	}
+
+	res, err := NewLogger(newTestLogger(&lines), mock, WithBodies(DefaultMaxBodySize)).RoundTrip(req)
+	assert.NoError(t, err)
+
+	reqBody, err := io.ReadAll(mock.req.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"test"}`, string(reqBody))
+
+	resBody, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.Contains(t, string(resBody), "secret-password")
+
+	output := strings.Join(lines, "\n")
+	assert.Contains(t, output, `{\"name\":\"test\"}`)
+	assert.Contains(t, output, `\"id\":\"abc\"`)
+	assert.Contains(t, output, redacted)
+	assert.NotContains(t, output, "secret-password")
+	assert.NotContains(t, output, "secret-token")
+}
+
+func TestLogBodiesTokenRequest(t *testing.T) {
+	var lines []string
+	mock := NewMock(&http.Response{
+		StatusCode: 200,
+		Body:       io.NopCloser(strings.NewReader(`{"access_token":"secret-token","expires_in":3600,"token_type":"bearer"}`)),
+	}, nil)
+
+	req := &http.Request{
+		Method: http.MethodPost,
+		URL:    mustParseURL(t, "https://api.neo4j.io/oauth/token"),
+		Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
+		Body:   io.NopCloser(strings.NewReader("client_id=id&client_secret=secret-client&grant_type=client_credentials")),
+	}
+
+	_, err := NewLogger(newTestLogger(&lines), mock, WithBodies(DefaultMaxBodySize)).RoundTrip(req)
+	assert.NoError(t, err)
+
+	output := strings.Join(lines, "\n")
+	assert.Contains(t, output, "grant_type=client_credentials")
+	assert.Contains(t, output, `\"expires_in\":3600`)
+	assert.NotContains(t, output, "secret-client")
+	assert.NotContains(t, output, "secret-token")
+}
+
+func TestLogBodiesTruncated(t *testing.T) {
+	var lines []string
+	mock := NewMock(&http.Response{
+		StatusCode: 500,
+		Body:       io.NopCloser(strings.NewReader(strings.Repeat("a", 100))),
+	}, nil)
+
+	req := &http.Request{Method: http.MethodGet, URL: mustParseURL(t, "https://api.neo4j.io/v1/instances")}
+	_, err := NewLogger(newTestLogger(&lines), mock, WithBodies(10)).RoundTrip(req)
+	assert.NoError(t, err)
+
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[1], `"body"="aaaaaaaaaa...(truncated)"`)
+}
+
+type failingBody struct {
+	closed bool
+}
+
+func (b *failingBody) Read(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func (b *failingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestLogBodiesReadError(t *testing.T) {
+	body := &failingBody{}
+	mock := NewMock(&http.Response{
+		StatusCode: 200,
+		Body:       body,
+	}, nil)
+
+	req := &http.Request{Method: http.MethodGet, URL: mustParseURL(t, "https://api.neo4j.io/v1/instances")}
+	res, err := NewLogger(logr.Discard(), mock, WithBodies(DefaultMaxBodySize)).RoundTrip(req)
+	assert.EqualError(t, err, "connection reset")
+	assert.Nil(t, res)
+	assert.True(t, body.closed)
+}
+
+func TestFormatBodyTruncatesOnRuneBoundary(t *testing.T) {
+	p := &log{maxBodySize: 4}
+	assert.Equal(t, "aä...(truncated)", p.formatBody(http.Header{}, []byte("aääa")))
+	assert.True(t, utf8.ValidString(p.formatBody(http.Header{}, []byte("ääää"))))
+
+	p.maxBodySize = 0
+	assert.Equal(t, "...(truncated)", p.formatBody(http.Header{}, []byte("ä")))
+}
+
+func TestRedactBody(t *testing.T) {
+	assert.Equal(t, `[{"password":"[REDACTED]"}]`, redactBody("application/json", []byte(`[{"password":"secret"}]`)))
+	assert.Equal(t, `{"id_token":"[REDACTED]","refresh_token":"[REDACTED]"}`, redactBody("application/json", []byte(`{"id_token":"secret","refresh_token":"secret"}`)))
+	assert.Equal(t, "grant_type=refresh_token&refresh_token=%5BREDACTED%5D", redactBody("application/x-www-form-urlencoded", []byte("grant_type=refresh_token&refresh_token=secret")))
+	assert.Equal(t, redacted, redactBody("text/plain", []byte(`password=secret`)))
+	assert.Equal(t, "not found", redactBody("text/plain", []byte("not found")))
+}
+
+func TestRedactHeaders(t *testing.T) {
+	header := redactHeaders(http.Header{
+		"Cookie":       []string{"session=secret"},
+		"Set-Cookie":   []string{"session=secret", "other=secret"},
+		"Content-Type": []string{"application/json"},
+	})
+
+	assert.Equal(t, []string{redacted}, header.Values("Cookie"))
+	assert.Equal(t, []string{redacted}, header.Values("Set-Cookie"))
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "aura",
+		Subsystem: "api",
+		Name:      "requests_total",
+		Help:      "Number of requests sent to the Aura API by endpoint, method and status class.",
+	}, []string{"endpoint", "method", "status"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "aura",
+		Subsystem: "api",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of requests sent to the Aura API by endpoint, method and status class.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint", "method", "status"})
+
+	tokenRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "aura",
+		Subsystem: "api",
+		Name:      "token_request_duration_seconds",
+		Help:      "Latency of requests sent to the OAuth2 token endpoint by status class.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"status"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(requestsTotal, requestDuration, tokenRequestDuration)
+}
+
+type metricsRecorder struct {
+	*endpoints
+	next http.RoundTripper
+}
+
+// NewMetrics records request counts and latencies.
+// Request paths are reduced to the first matching endpoint template, e.g. /instances/{instanceId},
+// to keep the number of label values bounded. Requests to tokenURL are recorded separately.
+func NewMetrics(next http.RoundTripper, tokenURL string, templates []string) *metricsRecorder {
+	return &metricsRecorder{
+		endpoints: newEndpoints(tokenURL, templates),
+		next:      next,
+	}
+}
+
+// statusClass returns the status class like 2xx of the response or error if the request failed
+func statusClass(res *http.Response, err error) string {
+	if err != nil || res == nil {
+		return "error"
+	}
+
+	return fmt.Sprintf("%dxx", res.StatusCode/100)
+}
+
+func (m *metricsRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	res, err := m.next.RoundTrip(req)
+	duration := time.Since(start).Seconds()
+	status := statusClass(res, err)
+
+	if m.isTokenRequest(req) {
+		tokenRequestDuration.WithLabelValues(status).Observe(duration)
+		return res, err
+	}
+
+	endpoint := m.endpoint(req.URL.Path)
+	requestsTotal.WithLabelValues(endpoint, req.Method, status).Inc()
+	requestDuration.WithLabelValues(endpoint, req.Method, status).Observe(duration)
+
+	return res, err
+}
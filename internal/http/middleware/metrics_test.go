@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/tj/assert"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	m := NewMetrics(nil, "", auraclient.Endpoints)
+
+	assert.Equal(t, "/instances", m.endpoint("/v1/instances"))
+	assert.Equal(t, "/instances/sizing", m.endpoint("/v1/instances/sizing"))
+	assert.Equal(t, "/instances/{instanceId}", m.endpoint("/v1/instances/abc123"))
+	assert.Equal(t, "/instances/{instanceId}/pause", m.endpoint("/v1/instances/abc123/pause"))
+	assert.Equal(t, "/instances/{instanceId}/snapshots/{snapshotId}/restore", m.endpoint("/v1/instances/abc/snapshots/def/restore"))
+	assert.Equal(t, "/tenants/{tenantId}", m.endpoint("/v1/tenants/xyz/"))
+	assert.Equal(t, "other", m.endpoint("/v1/unknown/abc"))
+}
+
+func TestMetricsRoundTrip(t *testing.T) {
+	requestsTotal.Reset()
+	requestDuration.Reset()
+	tokenRequestDuration.Reset()
+
+	m := NewMetrics(NewMock(&http.Response{StatusCode: 200}, nil), "https://api.neo4j.io/oauth/token", auraclient.Endpoints)
+
+	for _, u := range []string{
+		"https://api.neo4j.io/v1/instances/abc",
+		"https://api.neo4j.io/v1/instances/def",
+		"https://api.neo4j.io/oauth/token",
+	} {
+		req := &http.Request{Method: http.MethodGet, URL: mustParseURL(t, u)}
+		res, err := m.RoundTrip(req)
+		assert.NoError(t, err)
+		assert.Equal(t, 200, res.StatusCode)
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(requestsTotal.WithLabelValues("/instances/{instanceId}", "GET", "2xx")))
+	assert.Equal(t, 1, testutil.CollectAndCount(requestDuration))
+	assert.Equal(t, 1, testutil.CollectAndCount(tokenRequestDuration))
+
+	m = NewMetrics(NewMock(nil, errors.New("connection refused")), "", auraclient.Endpoints)
+	_, err := m.RoundTrip(&http.Request{Method: http.MethodPost, URL: mustParseURL(t, "https://api.neo4j.io/v1/instances")})
+	assert.Error(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(requestsTotal.WithLabelValues("/instances", "POST", "error")))
+
+	m = NewMetrics(NewMock(&http.Response{StatusCode: 503}, nil), "", auraclient.Endpoints)
+	_, err = m.RoundTrip(&http.Request{Method: http.MethodGet, URL: mustParseURL(t, "https://api.neo4j.io/v1/tenants/abc")})
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(requestsTotal.WithLabelValues("/tenants/{tenantId}", "GET", "5xx")))
+}
+
+func mustParseURL(t *testing.T, u string) *url.URL {
+	parsed, err := url.Parse(u)
+	assert.NoError(t, err)
+	return parsed
+}
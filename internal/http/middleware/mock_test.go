@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+type mock struct {
+	res *http.Response
+	err error
+	req *http.Request
+}
+
+// NewMock returns a RoundTripper which returns the given response and error
+func NewMock(res *http.Response, err error) *mock {
+	return &mock{
+		res: res,
+		err: err,
+	}
+}
+
+func (m *mock) RoundTrip(req *http.Request) (*http.Response, error) {
+	m.req = req
+	return m.res, m.err
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type tracer struct {
+	*endpoints
+	next http.RoundTripper
+}
+
+// NewTracing creates a span for each request named by the endpoint template of the request path
+func NewTracing(next http.RoundTripper, tokenURL string, templates []string) *tracer {
+	return &tracer{
+		endpoints: newEndpoints(tokenURL, templates),
+		next:      next,
+	}
+}
+
+func (t *tracer) RoundTrip(req *http.Request) (*http.Response, error) {
+	name := fmt.Sprintf("aura.api %s %s", req.Method, t.endpoint(req.URL.Path))
+	if t.isTokenRequest(req) {
+		name = "aura.api token"
+	}
+
+	ctx, span := tracing.Start(req.Context(), name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.path", req.URL.Path),
+		),
+	)
+	defer span.End()
+
+	res, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		tracing.RecordError(span, err)
+		return res, err
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", res.StatusCode))
+	if res.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, res.Status)
+	}
+
+	return res, err
+}
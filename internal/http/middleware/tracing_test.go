@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/tracing"
+	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
+	"github.com/tj/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestTracingRoundTrip(t *testing.T) {
+	recorder := newSpanRecorder(t)
+	ctx := tracing.ContextWithAttributes(context.Background(), tracing.AttributeInstanceID.String("abc"))
+
+	tr := NewTracing(NewMock(&http.Response{StatusCode: 200}, nil), "https://api.neo4j.io/oauth/token", auraclient.Endpoints)
+	for _, u := range []string{
+		"https://api.neo4j.io/v1/instances/abc",
+		"https://api.neo4j.io/oauth/token",
+	} {
+		req := (&http.Request{Method: http.MethodGet, URL: mustParseURL(t, u)}).WithContext(ctx)
+		_, err := tr.RoundTrip(req)
+		assert.NoError(t, err)
+	}
+
+	tr = NewTracing(NewMock(&http.Response{StatusCode: 404, Status: "404 Not Found"}, nil), "", auraclient.Endpoints)
+	_, err := tr.RoundTrip(&http.Request{Method: http.MethodDelete, URL: mustParseURL(t, "https://api.neo4j.io/v1/instances/abc")})
+	assert.NoError(t, err)
+
+	tr = NewTracing(NewMock(nil, errors.New("connection refused")), "", auraclient.Endpoints)
+	_, err = tr.RoundTrip(&http.Request{Method: http.MethodPost, URL: mustParseURL(t, "https://api.neo4j.io/v1/instances")})
+	assert.Error(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 4)
+
+	assert.Equal(t, "aura.api GET /instances/{instanceId}", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), tracing.AttributeInstanceID.String("abc"))
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("http.response.status_code", 200))
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	assert.Equal(t, "aura.api token", spans[1].Name())
+
+	assert.Equal(t, "aura.api DELETE /instances/{instanceId}", spans[2].Name())
+	assert.Equal(t, codes.Error, spans[2].Status().Code)
+
+	assert.Equal(t, "aura.api POST /instances", spans[3].Name())
+	assert.Equal(t, codes.Error, spans[3].Status().Code)
+	assert.Equal(t, "connection refused", spans[3].Status().Description)
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics exposes Prometheus metrics of the managed Aura instances
+package metrics
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	infrav1beta1 "github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "aura"
+
+var (
+	instanceInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "instance_info",
+		Help:      "Information about the Aura instance, the value is always 1.",
+	}, []string{"namespace", "name", "instance_id", "version", "tier", "region", "cloud_provider"})
+
+	instanceStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "instance_status",
+		Help:      "Status of the Aura instance as reported by Aura, the value is always 1.",
+	}, []string{"namespace", "name", "tier", "region", "status"})
+
+	instanceReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "instance_ready",
+		Help:      "Whether the Aura instance is ready (1) or not (0).",
+	}, []string{"namespace", "name"})
+
+	instancePaused = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "instance_paused",
+		Help:      "Whether the Aura instance is paused (1) or not (0).",
+	}, []string{"namespace", "name"})
+
+	instanceMemory = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "instance_memory_bytes",
+		Help:      "Memory size of the Aura instance in bytes.",
+	}, []string{"namespace", "name"})
+
+	instanceStorage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "instance_storage_bytes",
+		Help:      "Storage size of the Aura instance in bytes.",
+	}, []string{"namespace", "name"})
+
+	lastSuccessfulReconcile = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "instance_last_successful_reconcile_timestamp_seconds",
+		Help:      "Unix timestamp of the last reconciliation which resulted in a ready Aura instance.",
+	}, []string{"namespace", "name"})
+
+	reconcileFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "instance_reconcile_failures_total",
+		Help:      "Number of failed reconciliations and reconciliations of stalled Aura instances by the reason of the Ready condition.",
+	}, []string{"namespace", "name", "reason"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		instanceInfo,
+		instanceStatus,
+		instanceReady,
+		instancePaused,
+		instanceMemory,
+		instanceStorage,
+		lastSuccessfulReconcile,
+		reconcileFailures,
+	)
+}
+
+// RecordInstance records the instance details as reported by Aura
+func RecordInstance(instance infrav1beta1.AuraInstance, remote *auraclient.Instance) {
+	labels := prometheus.Labels{"namespace": instance.Namespace, "name": instance.Name}
+
+	instanceInfo.DeletePartialMatch(labels)
+	instanceInfo.WithLabelValues(instance.Namespace, instance.Name, remote.Data.Id, instance.Spec.Neo4jVersion,
+		string(remote.Data.Type), remote.Data.Region, string(remote.Data.CloudProvider)).Set(1)
+
+	instanceStatus.DeletePartialMatch(labels)
+	instanceStatus.WithLabelValues(instance.Namespace, instance.Name, string(remote.Data.Type), remote.Data.Region, string(remote.Data.Status)).Set(1)
+
+	paused := 0.0
+	if remote.Data.Status == auraclient.InstanceDataStatusPaused {
+		paused = 1
+	}
+	instancePaused.WithLabelValues(instance.Namespace, instance.Name).Set(paused)
+
+	if size, err := ParseSize(remote.Data.Memory); err == nil {
+		instanceMemory.WithLabelValues(instance.Namespace, instance.Name).Set(size)
+	}
+
+	if size, err := ParseSize(remote.Data.Storage); err == nil {
+		instanceStorage.WithLabelValues(instance.Namespace, instance.Name).Set(size)
+	}
+}
+
+// RecordReconcile records the result of a reconciliation
+func RecordReconcile(instance infrav1beta1.AuraInstance, err error, now time.Time) {
+	ready := conditions.IsTrue(&instance, infrav1beta1.ConditionReady)
+
+	value := 0.0
+	if ready {
+		value = 1
+	}
+	instanceReady.WithLabelValues(instance.Namespace, instance.Name).Set(value)
+
+	switch {
+	case err == nil && ready:
+		lastSuccessfulReconcile.WithLabelValues(instance.Namespace, instance.Name).Set(float64(now.Unix()))
+	// Instances which are waiting for Aura, paused or pending approval are not failing
+	case err != nil || conditions.IsTrue(&instance, infrav1beta1.ConditionStalled):
+		reconcileFailures.WithLabelValues(instance.Namespace, instance.Name, conditions.GetReason(&instance, infrav1beta1.ConditionReady)).Inc()
+	}
+}
+
+// Delete removes all metrics of a deleted instance
+func Delete(namespace, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+	for _, vec := range []*prometheus.MetricVec{
+		instanceInfo.MetricVec,
+		instanceStatus.MetricVec,
+		instanceReady.MetricVec,
+		instancePaused.MetricVec,
+		instanceMemory.MetricVec,
+		instanceStorage.MetricVec,
+		lastSuccessfulReconcile.MetricVec,
+		reconcileFailures.MetricVec,
+	} {
+		vec.DeletePartialMatch(labels)
+	}
+}
+
+var sizePattern = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)\s*([KMGT]i?B)?$`)
+
+var sizeUnits = map[string]float64{
+	"":    1 << 30,
+	"KB":  1 << 10,
+	"MB":  1 << 20,
+	"GB":  1 << 30,
+	"TB":  1 << 40,
+	"KiB": 1 << 10,
+	"MiB": 1 << 20,
+	"GiB": 1 << 30,
+	"TiB": 1 << 40,
+}
+
+// ParseSize converts an Aura size like 8GB into bytes, sizes without unit are in GB
+func ParseSize(size string) (float64, error) {
+	match := sizePattern.FindStringSubmatch(size)
+	if match == nil {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return value * sizeUnits[match[2]], nil
+}
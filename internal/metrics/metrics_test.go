@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	infrav1beta1 "github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
+	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/tj/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseSize(t *testing.T) {
+	for size, expected := range map[string]float64{
+		"8GB":    8 << 30,
+		"1.5GB":  1.5 * (1 << 30),
+		"512MB":  512 << 20,
+		"2TB":    2 << 40,
+		"16":     16 << 30,
+		"4 GiB":  4 << 30,
+		"256KiB": 256 << 10,
+	} {
+		value, err := ParseSize(size)
+		assert.NoError(t, err, size)
+		assert.Equal(t, expected, value, size)
+	}
+
+	_, err := ParseSize("")
+	assert.Error(t, err)
+
+	_, err = ParseSize("large")
+	assert.Error(t, err)
+}
+
+func newInstance() infrav1beta1.AuraInstance {
+	return infrav1beta1.AuraInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "instance",
+			Namespace: "metrics",
+		},
+		Spec: infrav1beta1.AuraInstanceSpec{
+			Neo4jVersion: "5",
+		},
+	}
+}
+
+func TestRecordInstance(t *testing.T) {
+	defer Delete("metrics", "instance")
+
+	remote := &auraclient.Instance{}
+	remote.Data.Id = "abc"
+	remote.Data.Type = "professional-db"
+	remote.Data.Region = "europe-west1"
+	remote.Data.CloudProvider = "gcp"
+	remote.Data.Status = auraclient.InstanceDataStatusRunning
+	remote.Data.Memory = "8GB"
+	remote.Data.Storage = "16GB"
+
+	RecordInstance(newInstance(), remote)
+	assert.Equal(t, float64(1), testutil.ToFloat64(instanceInfo.WithLabelValues("metrics", "instance", "abc", "5", "professional-db", "europe-west1", "gcp")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(instanceStatus.WithLabelValues("metrics", "instance", "professional-db", "europe-west1", "running")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(instancePaused.WithLabelValues("metrics", "instance")))
+	assert.Equal(t, float64(8<<30), testutil.ToFloat64(instanceMemory.WithLabelValues("metrics", "instance")))
+	assert.Equal(t, float64(16<<30), testutil.ToFloat64(instanceStorage.WithLabelValues("metrics", "instance")))
+
+	// the previous status is replaced
+	remote.Data.Status = auraclient.InstanceDataStatusPaused
+	RecordInstance(newInstance(), remote)
+	assert.Equal(t, 1, testutil.CollectAndCount(instanceStatus))
+	assert.Equal(t, float64(1), testutil.ToFloat64(instancePaused.WithLabelValues("metrics", "instance")))
+}
+
+func TestRecordReconcile(t *testing.T) {
+	defer Delete("metrics", "instance")
+	now := time.Unix(1700000000, 0)
+
+	instance := infrav1beta1.AuraInstanceReady(newInstance(), metav1.ConditionTrue, "InstanceRunning", "")
+	RecordReconcile(instance, nil, now)
+	assert.Equal(t, float64(1), testutil.ToFloat64(instanceReady.WithLabelValues("metrics", "instance")))
+	assert.Equal(t, float64(now.Unix()), testutil.ToFloat64(lastSuccessfulReconcile.WithLabelValues("metrics", "instance")))
+
+	instance = infrav1beta1.AuraInstanceReady(instance, metav1.ConditionFalse, "ReconciliationFailed", "")
+	RecordReconcile(instance, errors.New("failed"), now.Add(time.Minute))
+	RecordReconcile(instance, errors.New("failed"), now.Add(time.Minute))
+	assert.Equal(t, float64(0), testutil.ToFloat64(instanceReady.WithLabelValues("metrics", "instance")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(reconcileFailures.WithLabelValues("metrics", "instance", "ReconciliationFailed")))
+	assert.Equal(t, float64(now.Unix()), testutil.ToFloat64(lastSuccessfulReconcile.WithLabelValues("metrics", "instance")))
+
+	instance = infrav1beta1.AuraInstanceReady(instance, metav1.ConditionFalse, "InstanceUpdating", "")
+	RecordReconcile(instance, nil, now.Add(time.Minute))
+	assert.Equal(t, float64(0), testutil.ToFloat64(reconcileFailures.WithLabelValues("metrics", "instance", "InstanceUpdating")))
+
+	instance = infrav1beta1.AuraInstanceStalled(instance, metav1.ConditionTrue, "InstanceSuspended", "")
+	instance = infrav1beta1.AuraInstanceReady(instance, metav1.ConditionFalse, "InstanceSuspended", "")
+	RecordReconcile(instance, nil, now.Add(time.Minute))
+	assert.Equal(t, float64(1), testutil.ToFloat64(reconcileFailures.WithLabelValues("metrics", "instance", "InstanceSuspended")))
+
+	Delete("metrics", "instance")
+	assert.Equal(t, 0, testutil.CollectAndCount(reconcileFailures))
+}
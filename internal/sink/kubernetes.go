@@ -0,0 +1,137 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ErrNotControlled is returned if a secret with the same name exists which is not controlled by the owner
+var ErrNotControlled = errors.New("secret is not controlled by the owner")
+
+// Kubernetes writes the connection secret to a Kubernetes secret controlled by the owner
+type Kubernetes struct {
+	client client.Client
+	owner  client.Object
+	name   string
+}
+
+// NewKubernetes returns a sink writing to the secret with the given name in the namespace of the owner
+func NewKubernetes(c client.Client, owner client.Object, name string) *Kubernetes {
+	return &Kubernetes{
+		client: c,
+		owner:  owner,
+		name:   name,
+	}
+}
+
+func (k *Kubernetes) Write(ctx context.Context, data map[string][]byte) error {
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      k.name,
+			Namespace: k.owner.GetNamespace(),
+		},
+	}
+
+	err := k.client.Get(ctx, client.ObjectKeyFromObject(&secret), &secret)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	exists := err == nil
+	controlled := metav1.IsControlledBy(&secret, k.owner)
+
+	if exists && controlled && equality.Semantic.DeepEqual(secret.Data, data) {
+		return nil
+	}
+
+	// Secrets which don't belong to the owner are never taken over as they might be managed by someone else
+	if exists && !controlled && !IsOwnedBy(&secret, k.owner) {
+		return fmt.Errorf("failed to update secret %s: %w", k.name, ErrNotControlled)
+	}
+
+	// Owner references created by earlier versions are replaced by a controller reference
+	secret.OwnerReferences = slices.DeleteFunc(secret.OwnerReferences, func(ref metav1.OwnerReference) bool {
+		return ref.UID == k.owner.GetUID()
+	})
+
+	if err := controllerutil.SetControllerReference(k.owner, &secret, k.client.Scheme()); err != nil {
+		return fmt.Errorf("failed to set owner reference: %w", err)
+	}
+
+	secret.Data = data
+
+	if !exists {
+		if err := k.client.Create(ctx, &secret); err != nil {
+			return fmt.Errorf("failed to create secret: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := k.client.Update(ctx, &secret); err != nil {
+		return fmt.Errorf("failed to update secret: %w", err)
+	}
+
+	return nil
+}
+
+func (k *Kubernetes) Delete(ctx context.Context) error {
+	var secret corev1.Secret
+	err := k.client.Get(ctx, types.NamespacedName{
+		Name:      k.name,
+		Namespace: k.owner.GetNamespace(),
+	}, &secret)
+
+	if kerrors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	// Secrets which don't belong to the owner are left untouched
+	if !IsOwnedBy(&secret, k.owner) {
+		return nil
+	}
+
+	if err := k.client.Delete(ctx, &secret); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete secret: %w", err)
+	}
+
+	return nil
+}
+
+// IsOwnedBy reports whether the object has an owner reference to the owner.
+// Earlier versions referenced the owner without marking it as the controller.
+func IsOwnedBy(obj, owner metav1.Object) bool {
+	return slices.ContainsFunc(obj.GetOwnerReferences(), func(ref metav1.OwnerReference) bool {
+		return ref.UID == owner.GetUID()
+	})
+}
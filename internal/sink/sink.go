@@ -0,0 +1,31 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sink writes connection secrets to secret stores
+package sink
+
+import (
+	"context"
+)
+
+// Sink stores the contents of a connection secret
+type Sink interface {
+	// Write creates or replaces the secret contents
+	Write(ctx context.Context, data map[string][]byte) error
+
+	// Delete removes the secret, a secret which does not exist is no error
+	Delete(ctx context.Context) error
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultVaultMount is the mount path of the KV version 2 secrets engine if none is configured
+const DefaultVaultMount = "secret"
+
+// VaultOptions configures the Vault sink
+type VaultOptions struct {
+	// Address of the Vault server
+	Address string
+
+	// Namespace is the Vault Enterprise namespace, empty for the root namespace
+	Namespace string
+
+	// Mount path of the KV version 2 secrets engine
+	Mount string
+
+	// Path of the secret within the secrets engine
+	Path string
+
+	// Token used to authenticate against Vault
+	Token string
+
+	// HTTPClient used for requests, defaults to a client with a 30s timeout
+	HTTPClient *http.Client
+}
+
+// Vault writes the connection secret to a HashiCorp Vault KV version 2 secrets engine
+type Vault struct {
+	opts VaultOptions
+}
+
+// NewVault returns a sink writing to Vault
+func NewVault(opts VaultOptions) (*Vault, error) {
+	if opts.Address == "" {
+		return nil, fmt.Errorf("vault address is required")
+	}
+
+	if opts.Path == "" {
+		return nil, fmt.Errorf("vault secret path is required")
+	}
+
+	if opts.Mount == "" {
+		opts.Mount = DefaultVaultMount
+	}
+
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &Vault{opts: opts}, nil
+}
+
+// Write stores the data as new secret version.
+// No version is created if the latest version already contains the data.
+func (v *Vault) Write(ctx context.Context, data map[string][]byte) error {
+	desired := make(map[string]string, len(data))
+	for key, value := range data {
+		desired[key] = string(value)
+	}
+
+	current, err := v.read(ctx)
+	if err != nil {
+		return err
+	}
+
+	if current != nil && maps.Equal(current, desired) {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]any{"data": desired})
+	if err != nil {
+		return err
+	}
+
+	res, err := v.do(ctx, http.MethodPost, "data", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
+		return vaultError("write", res)
+	}
+
+	return nil
+}
+
+// Delete removes all versions and the metadata of the secret
+func (v *Vault) Delete(ctx context.Context) error {
+	res, err := v.do(ctx, http.MethodDelete, "metadata", nil)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent && res.StatusCode != http.StatusNotFound {
+		return vaultError("delete", res)
+	}
+
+	return nil
+}
+
+// read returns the data of the latest secret version, nil if the secret does not exist or the version is deleted
+func (v *Vault) read(ctx context.Context) (map[string]string, error) {
+	res, err := v.do(ctx, http.MethodGet, "data", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, vaultError("read", res)
+	}
+
+	var secret struct {
+		Data struct {
+			Data map[string]string `json:"data"`
+		} `json:"data"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&secret); err != nil {
+		return nil, fmt.Errorf("failed to decode vault secret: %w", err)
+	}
+
+	return secret.Data.Data, nil
+}
+
+func (v *Vault) do(ctx context.Context, method, kind string, body io.Reader) (*http.Response, error) {
+	u, err := url.JoinPath(v.opts.Address, "v1", v.opts.Mount, kind, strings.TrimPrefix(v.opts.Path, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault address: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-Vault-Token", v.opts.Token)
+	if v.opts.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.opts.Namespace)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := v.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request failed: %w", err)
+	}
+
+	return res, nil
+}
+
+// vaultError returns the errors reported by Vault
+func vaultError(op string, res *http.Response) error {
+	var body struct {
+		Errors []string `json:"errors"`
+	}
+
+	_ = json.NewDecoder(res.Body).Decode(&body)
+	return fmt.Errorf("failed to %s vault secret, request failed with code %d - %s", op, res.StatusCode, strings.Join(body.Errors, ", "))
+}
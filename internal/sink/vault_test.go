@@ -0,0 +1,154 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+// fakeVault implements the KV version 2 endpoints of a Vault server
+type fakeVault struct {
+	mu       sync.Mutex
+	token    string
+	versions map[string][]map[string]string
+}
+
+func newFakeVault(token string) (*fakeVault, *httptest.Server) {
+	v := &fakeVault{
+		token:    token,
+		versions: make(map[string][]map[string]string),
+	}
+
+	return v, httptest.NewServer(v)
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if r.Header.Get("X-Vault-Token") != v.token {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+
+	path, ok := strings.CutPrefix(r.URL.Path, "/v1/secret/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "data/"):
+		versions := v.versions[strings.TrimPrefix(path, "data/")]
+		if len(versions) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{
+				"data":     versions[len(versions)-1],
+				"metadata": map[string]any{"version": len(versions)},
+			},
+		})
+	case r.Method == http.MethodPost && strings.HasPrefix(path, "data/"):
+		var body struct {
+			Data map[string]string `json:"data"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		key := strings.TrimPrefix(path, "data/")
+		v.versions[key] = append(v.versions[key], body.Data)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{"version": len(v.versions[key])},
+		})
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, "metadata/"):
+		delete(v.versions, strings.TrimPrefix(path, "metadata/"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestVaultWrite(t *testing.T) {
+	fake, server := newFakeVault("token")
+	defer server.Close()
+
+	v, err := NewVault(VaultOptions{
+		Address: server.URL,
+		Path:    "apps/my-app/neo4j",
+		Token:   "token",
+	})
+	assert.NoError(t, err)
+
+	data := map[string][]byte{
+		"username": []byte("neo4j"),
+		"password": []byte("secret"),
+	}
+
+	assert.NoError(t, v.Write(context.TODO(), data))
+	assert.Equal(t, []map[string]string{{"username": "neo4j", "password": "secret"}}, fake.versions["apps/my-app/neo4j"])
+
+	// unchanged data does not create a new version
+	assert.NoError(t, v.Write(context.TODO(), data))
+	assert.Len(t, fake.versions["apps/my-app/neo4j"], 1)
+
+	data["password"] = []byte("rotated")
+	assert.NoError(t, v.Write(context.TODO(), data))
+	assert.Len(t, fake.versions["apps/my-app/neo4j"], 2)
+	assert.Equal(t, "rotated", fake.versions["apps/my-app/neo4j"][1]["password"])
+}
+
+func TestVaultDelete(t *testing.T) {
+	fake, server := newFakeVault("token")
+	defer server.Close()
+
+	v, err := NewVault(VaultOptions{
+		Address: server.URL,
+		Path:    "/apps/my-app/neo4j",
+		Token:   "token",
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, v.Write(context.TODO(), map[string][]byte{"password": []byte("secret")}))
+	assert.NoError(t, v.Delete(context.TODO()))
+	assert.Empty(t, fake.versions)
+
+	// deleting a secret which does not exist succeeds
+	assert.NoError(t, v.Delete(context.TODO()))
+}
+
+func TestVaultPermissionDenied(t *testing.T) {
+	_, server := newFakeVault("token")
+	defer server.Close()
+
+	v, err := NewVault(VaultOptions{
+		Address: server.URL,
+		Path:    "apps/my-app/neo4j",
+		Token:   "invalid",
+	})
+	assert.NoError(t, err)
+
+	err = v.Write(context.TODO(), map[string][]byte{"password": []byte("secret")})
+	assert.EqualError(t, err, "failed to read vault secret, request failed with code 403 - permission denied")
+}
+
+func TestNewVaultValidation(t *testing.T) {
+	_, err := NewVault(VaultOptions{Path: "a"})
+	assert.Error(t, err)
+
+	_, err = NewVault(VaultOptions{Address: "http://vault:8200"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// tracingClient creates a span for each write to the Kubernetes API
+type tracingClient struct {
+	client.Client
+}
+
+// WrapClient returns a client which traces writes to the Kubernetes API
+func WrapClient(c client.Client) client.Client {
+	return &tracingClient{Client: c}
+}
+
+func (c *tracingClient) start(ctx context.Context, verb string, obj client.Object) (context.Context, trace.Span) {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if gvk, err := c.GroupVersionKindFor(obj); err == nil {
+		kind = gvk.Kind
+	}
+
+	return Start(ctx, fmt.Sprintf("k8s.%s %s", verb, kind),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			AttributeNamespace.String(obj.GetNamespace()),
+			AttributeName.String(obj.GetName()),
+		),
+	)
+}
+
+func (c *tracingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	ctx, span := c.start(ctx, "create", obj)
+	defer span.End()
+
+	err := c.Client.Create(ctx, obj, opts...)
+	RecordError(span, err)
+	return err
+}
+
+func (c *tracingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	ctx, span := c.start(ctx, "update", obj)
+	defer span.End()
+
+	err := c.Client.Update(ctx, obj, opts...)
+	RecordError(span, err)
+	return err
+}
+
+func (c *tracingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	ctx, span := c.start(ctx, "patch", obj)
+	defer span.End()
+
+	err := c.Client.Patch(ctx, obj, patch, opts...)
+	RecordError(span, err)
+	return err
+}
+
+func (c *tracingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	ctx, span := c.start(ctx, "delete", obj)
+	defer span.End()
+
+	err := c.Client.Delete(ctx, obj, opts...)
+	RecordError(span, err)
+	return err
+}
+
+func (c *tracingClient) Status() client.SubResourceWriter {
+	return &tracingSubResourceWriter{
+		SubResourceWriter: c.Client.Status(),
+		client:            c,
+		subResource:       "status",
+	}
+}
+
+// tracingSubResourceWriter creates a span for each write to a subresource
+type tracingSubResourceWriter struct {
+	client.SubResourceWriter
+	client      *tracingClient
+	subResource string
+}
+
+func (w *tracingSubResourceWriter) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	ctx, span := w.client.start(ctx, "create."+w.subResource, obj)
+	defer span.End()
+
+	err := w.SubResourceWriter.Create(ctx, obj, subResource, opts...)
+	RecordError(span, err)
+	return err
+}
+
+func (w *tracingSubResourceWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	ctx, span := w.client.start(ctx, "update."+w.subResource, obj)
+	defer span.End()
+
+	err := w.SubResourceWriter.Update(ctx, obj, opts...)
+	RecordError(span, err)
+	return err
+}
+
+func (w *tracingSubResourceWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	ctx, span := w.client.start(ctx, "patch."+w.subResource, obj)
+	defer span.End()
+
+	err := w.SubResourceWriter.Patch(ctx, obj, patch, opts...)
+	RecordError(span, err)
+	return err
+}
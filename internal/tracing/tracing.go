@@ -0,0 +1,119 @@
+/*
+Copyright 2025 Doodle.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing traces reconciliations, Aura API calls and Kubernetes writes using OpenTelemetry
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	flag "github.com/spf13/pflag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/doodlescheduling/neo4j-aura-controller"
+
+// Attribute keys set on spans
+const (
+	AttributeNamespace  = attribute.Key("k8s.namespace.name")
+	AttributeName       = attribute.Key("k8s.object.name")
+	AttributeInstanceID = attribute.Key("aura.instance.id")
+	AttributeTenantID   = attribute.Key("aura.tenant.id")
+)
+
+// Options configures the export of traces
+type Options struct {
+	Endpoint    string
+	Insecure    bool
+	SampleRatio float64
+}
+
+// BindFlags binds the tracing options to command line flags
+func (o *Options) BindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Endpoint, "otlp-endpoint", "",
+		"The OTLP HTTP endpoint traces are exported to, e.g. otel-collector:4318. Tracing is disabled if not set.")
+	fs.BoolVar(&o.Insecure, "otlp-insecure", false,
+		"Export traces to the OTLP endpoint without TLS.")
+	fs.Float64Var(&o.SampleRatio, "trace-sample-ratio", 1,
+		"The ratio of traces which are sampled, between 0 and 1.")
+}
+
+// Setup registers a tracer provider exporting traces to the OTLP endpoint.
+// Tracing is disabled if no endpoint is configured, the returned function flushes and stops the export.
+func Setup(ctx context.Context, opts Options, serviceName string) (func(context.Context) error, error) {
+	if opts.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporterOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(opts.Endpoint)}
+	if opts.Insecure {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create otlp exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+type attributesKey struct{}
+
+// ContextWithAttributes returns a context whose child spans carry the attributes
+func ContextWithAttributes(ctx context.Context, attrs ...attribute.KeyValue) context.Context {
+	return context.WithValue(ctx, attributesKey{}, append(slices.Clone(Attributes(ctx)), attrs...))
+}
+
+// Attributes returns the attributes child spans of the context carry
+func Attributes(ctx context.Context) []attribute.KeyValue {
+	attrs, _ := ctx.Value(attributesKey{}).([]attribute.KeyValue)
+	return attrs
+}
+
+// Start starts a span carrying the attributes of the context
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	opts = append(opts, trace.WithAttributes(Attributes(ctx)...))
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// RecordError marks the span as failed
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tj/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestSetupDisabled(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Options{}, "test")
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestContextWithAttributes(t *testing.T) {
+	ctx := ContextWithAttributes(context.Background(), AttributeTenantID.String("tenant"))
+	child := ContextWithAttributes(ctx, AttributeInstanceID.String("abc"))
+
+	assert.Equal(t, 1, len(Attributes(ctx)))
+	assert.Equal(t, 2, len(Attributes(child)))
+	assert.Empty(t, Attributes(context.Background()))
+}
+
+func TestStart(t *testing.T) {
+	recorder := newSpanRecorder(t)
+
+	ctx := ContextWithAttributes(context.Background(), AttributeInstanceID.String("abc"))
+	_, span := Start(ctx, "test")
+	RecordError(span, nil)
+	RecordError(span, errors.New("failed"))
+	span.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "test", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), AttributeInstanceID.String("abc"))
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Len(t, spans[0].Events(), 1)
+}
+
+func TestWrapClient(t *testing.T) {
+	recorder := newSpanRecorder(t)
+
+	c := WrapClient(fake.NewClientBuilder().WithScheme(scheme.Scheme).Build())
+	ctx := ContextWithAttributes(context.Background(), AttributeTenantID.String("tenant"))
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "default"}}
+	assert.NoError(t, c.Create(ctx, secret))
+
+	secret.StringData = map[string]string{"key": "value"}
+	assert.NoError(t, c.Update(ctx, secret))
+	assert.NoError(t, c.Patch(ctx, secret, client.MergeFrom(secret.DeepCopy())))
+	assert.NoError(t, c.Delete(ctx, secret))
+	assert.Error(t, c.Delete(ctx, secret))
+
+	var secrets corev1.SecretList
+	assert.NoError(t, c.List(ctx, &secrets))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 5)
+	assert.Equal(t, "k8s.create Secret", spans[0].Name())
+	assert.Equal(t, "k8s.update Secret", spans[1].Name())
+	assert.Equal(t, "k8s.patch Secret", spans[2].Name())
+	assert.Equal(t, "k8s.delete Secret", spans[3].Name())
+	assert.Equal(t, codes.Unset, spans[3].Status().Code)
+	assert.Equal(t, codes.Error, spans[4].Status().Code)
+
+	assert.Contains(t, spans[0].Attributes(), AttributeTenantID.String("tenant"))
+	assert.Contains(t, spans[0].Attributes(), AttributeNamespace.String("default"))
+	assert.Contains(t, spans[0].Attributes(), AttributeName.String("secret"))
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -25,6 +26,8 @@ import (
 	infrav1beta1 "github.com/doodlescheduling/neo4j-aura-controller/api/v1beta1"
 	"github.com/doodlescheduling/neo4j-aura-controller/internal/controllers"
 	"github.com/doodlescheduling/neo4j-aura-controller/internal/http/middleware"
+	"github.com/doodlescheduling/neo4j-aura-controller/internal/tracing"
+	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
 	"github.com/fluxcd/pkg/runtime/client"
 	helper "github.com/fluxcd/pkg/runtime/controller"
 	"github.com/fluxcd/pkg/runtime/leaderelection"
@@ -69,6 +72,12 @@ var (
 	watchOptions            helper.WatchOptions
 	baseURL                 string
 	tokenURL                string
+	defaultInterval         time.Duration
+	dryRun                  bool
+	replicationNamespaces   []string
+	logHTTPBodies           bool
+	logHTTPBodyMaxSize      int
+	tracingOptions          tracing.Options
 )
 
 func main() {
@@ -84,6 +93,16 @@ func main() {
 		"The base API URL for neo4j Aura.")
 	flag.StringVar(&tokenURL, "token-url", "https://api.neo4j.io/oauth/token",
 		"The OAuth2 token endpoint URL for neo4j Aura. Use for the client credentials flow.")
+	flag.DurationVar(&defaultInterval, "default-interval", 10*time.Minute,
+		"The interval at which AuraInstances are reconciled to detect drift if spec.interval is not set. Use 0 to disable.")
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"Only plan changes and record them in the AuraInstance status without calling mutating Aura APIs.")
+	flag.StringSliceVar(&replicationNamespaces, "replication-allowed-namespaces", nil,
+		"The namespaces or glob patterns connection secrets may be replicated to. Replication is disabled if not set.")
+	flag.BoolVar(&logHTTPBodies, "log-http-bodies", false,
+		"Log the bodies of requests sent to the Aura API on the debug level. Passwords, tokens and client secrets are redacted.")
+	flag.IntVar(&logHTTPBodyMaxSize, "log-http-body-max-size", middleware.DefaultMaxBodySize,
+		"The number of bytes of a logged request or response body after which it is truncated.")
 
 	clientOptions.BindFlags(flag.CommandLine)
 	logOptions.BindFlags(flag.CommandLine)
@@ -91,10 +110,39 @@ func main() {
 	rateLimiterOptions.BindFlags(flag.CommandLine)
 	kubeConfigOpts.BindFlags(flag.CommandLine)
 	watchOptions.BindFlags(flag.CommandLine)
+	tracingOptions.BindFlags(flag.CommandLine)
 
 	flag.Parse()
 	logger.SetLogger(logger.NewLogger(logOptions))
 
+	if logHTTPBodyMaxSize < 0 {
+		setupLog.Error(fmt.Errorf("must not be negative, got %d", logHTTPBodyMaxSize), "invalid --log-http-body-max-size")
+		os.Exit(1)
+	}
+
+	if err := run(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// run sets up and starts the manager.
+// Pending spans are flushed before it returns, also if the setup fails.
+func run() error {
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingOptions, controllerName)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		return err
+	}
+
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := shutdownTracing(ctx); err != nil {
+			setupLog.Error(err, "failed to flush traces")
+		}
+	}()
+
 	leaderElectionId := fmt.Sprintf("%s-%s", controllerName, "leader-election")
 	if watchOptions.LabelSelector != "" {
 		leaderElectionId = leaderelection.GenerateID(leaderElectionId, watchOptions.LabelSelector)
@@ -108,7 +156,7 @@ func main() {
 	watchSelector, err := helper.GetWatchSelector(watchOptions)
 	if err != nil {
 		setupLog.Error(err, "unable to configure watch label selector for manager")
-		os.Exit(1)
+		return err
 	}
 
 	watchNs := make(map[string]ctrlcache.Config)
@@ -137,51 +185,97 @@ func main() {
 		},
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), opts)
+	restConfig, err := ctrl.GetConfig()
+	if err != nil {
+		setupLog.Error(err, "unable to get kubeconfig")
+		return err
+	}
+
+	mgr, err := ctrl.NewManager(restConfig, opts)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
-		os.Exit(1)
+		return err
 	}
 
 	// Add liveness probe
 	err = mgr.AddHealthzCheck("healthz", healthz.Ping)
 	if err != nil {
 		setupLog.Error(err, "Could not add liveness probe")
-		os.Exit(1)
+		return err
 	}
 
 	// Add readiness probe
 	err = mgr.AddReadyzCheck("readyz", healthz.Ping)
 	if err != nil {
 		setupLog.Error(err, "Could not add readiness probe")
-		os.Exit(1)
+		return err
 	}
 
 	logger := ctrl.Log.WithName("controllers").WithName("AuraInstance")
+	var logOpts []middleware.LogOption
+	if logHTTPBodies {
+		logOpts = append(logOpts, middleware.WithBodies(logHTTPBodyMaxSize))
+	}
+
 	httpClient := &http.Client{
-		Transport: middleware.NewLogger(logger, http.DefaultTransport),
+		Transport: middleware.NewTracing(
+			middleware.NewMetrics(middleware.NewLogger(logger, http.DefaultTransport, logOpts...), tokenURL, auraclient.Endpoints),
+			tokenURL, auraclient.Endpoints,
+		),
 	}
 
 	AuraInstanceReconciler := &controllers.AuraInstanceReconciler{
-		Client:     mgr.GetClient(),
-		HTTPClient: httpClient,
-		BaseURL:    baseURL,
-		TokenURL:   tokenURL,
-		Log:        logger,
-		Recorder:   mgr.GetEventRecorderFor("AuraInstance"),
+		Client:          tracing.WrapClient(mgr.GetClient()),
+		HTTPClient:      httpClient,
+		BaseURL:         baseURL,
+		TokenURL:        tokenURL,
+		Log:             logger,
+		Recorder:        mgr.GetEventRecorderFor("AuraInstance"),
+		DefaultInterval: defaultInterval,
+		DryRun:          dryRun,
+
+		ReplicationAllowedNamespaces: replicationNamespaces,
 	}
 
 	if err = AuraInstanceReconciler.SetupWithManager(mgr, controllers.AuraInstanceReconcilerOptions{
 		MaxConcurrentReconciles: concurrent,
 	}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AuraInstance")
-		os.Exit(1)
+		return err
+	}
+
+	AuraDatabaseUserReconciler := &controllers.AuraDatabaseUserReconciler{
+		Client:   tracing.WrapClient(mgr.GetClient()),
+		Log:      ctrl.Log.WithName("controllers").WithName("AuraDatabaseUser"),
+		Recorder: mgr.GetEventRecorderFor("AuraDatabaseUser"),
+	}
+
+	if err = AuraDatabaseUserReconciler.SetupWithManager(mgr, controllers.AuraDatabaseUserReconcilerOptions{
+		MaxConcurrentReconciles: concurrent,
+	}); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "AuraDatabaseUser")
+		return err
+	}
+
+	AuraSchemaMigrationReconciler := &controllers.AuraSchemaMigrationReconciler{
+		Client:   tracing.WrapClient(mgr.GetClient()),
+		Log:      ctrl.Log.WithName("controllers").WithName("AuraSchemaMigration"),
+		Recorder: mgr.GetEventRecorderFor("AuraSchemaMigration"),
+	}
+
+	if err = AuraSchemaMigrationReconciler.SetupWithManager(mgr, controllers.AuraSchemaMigrationReconcilerOptions{
+		MaxConcurrentReconciles: concurrent,
+	}); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "AuraSchemaMigration")
+		return err
 	}
 
 	// +kubebuilder:scaffold:builder
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
-		os.Exit(1)
+		return err
 	}
+
+	return nil
 }
@@ -0,0 +1,24 @@
+package client
+
+// Endpoints are the path templates of the Aura API operations.
+// More specific templates are listed before templates with a parameter at the same position.
+var Endpoints = []string{
+	"/customer-managed-keys",
+	"/customer-managed-keys/{customerManagedKeyId}",
+	"/graph-analytics/sessions",
+	"/graph-analytics/sessions/sizing",
+	"/graph-analytics/sessions/{sessionId}",
+	"/instances",
+	"/instances/sizing",
+	"/instances/{instanceId}",
+	"/instances/{instanceId}/overwrite",
+	"/instances/{instanceId}/pause",
+	"/instances/{instanceId}/resume",
+	"/instances/{instanceId}/snapshots",
+	"/instances/{instanceId}/snapshots/{snapshotId}",
+	"/instances/{instanceId}/snapshots/{snapshotId}/restore",
+	"/instances/{instanceId}/upgrade",
+	"/tenants",
+	"/tenants/{tenantId}",
+	"/tenants/{tenantId}/metrics-integration",
+}
@@ -0,0 +1,876 @@
+// Package fake implements an in-process Aura API server for tests and local development.
+// Instances move through the same states as real Aura instances, transitions complete after a configurable delay.
+package fake
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
+)
+
+const (
+	// APIPrefix is the path prefix of the Aura API endpoints
+	APIPrefix = "/v1"
+
+	// TokenPath is the path of the OAuth2 token endpoint
+	TokenPath = "/oauth/token"
+
+	tokenTTL = time.Hour
+)
+
+// Snapshot states
+const (
+	SnapshotInProgress = "InProgress"
+	SnapshotCompleted  = "Completed"
+)
+
+// statusDestroyed is the final state of a deleted instance, the instance is removed once it is reached
+const statusDestroyed auraclient.InstanceDataStatus = "destroyed"
+
+// Project is an Aura project (tenant) instances can be created in
+type Project struct {
+	ID   string
+	Name string
+}
+
+// Failure is an error response which is returned instead of handling a matching request
+type Failure struct {
+	// Method matches the HTTP method of a request, all methods are matched if empty
+	Method string
+
+	// Endpoint matches the path template of an Aura API operation as listed in auraclient.Endpoints,
+	// e.g. /instances/{instanceId}, or TokenPath. All endpoints are matched if empty.
+	Endpoint string
+
+	// StatusCode of the response, defaults to 500
+	StatusCode int
+
+	// Body of the response, defaults to an Aura error
+	Body string
+
+	// Times limits how often the failure is returned, it is returned until the failures are cleared if 0
+	Times int
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithTransitionDelay sets the time after which an instance or snapshot in a transient state reaches its next state.
+// Transitions complete with the next request by default.
+func WithTransitionDelay(d time.Duration) Option {
+	return func(s *Server) {
+		s.delay = d
+	}
+}
+
+// WithStatusDelay overrides the transition delay of a single transient instance status, e.g. creating
+func WithStatusDelay(status auraclient.InstanceDataStatus, d time.Duration) Option {
+	return func(s *Server) {
+		s.delays[status] = d
+	}
+}
+
+// WithLatency delays every response
+func WithLatency(d time.Duration) Option {
+	return func(s *Server) {
+		s.latency = d
+	}
+}
+
+// WithCredentials only accepts the given client credentials at the token endpoint.
+// Any client credentials are accepted by default.
+func WithCredentials(clientID, clientSecret string) Option {
+	return func(s *Server) {
+		s.clientID = clientID
+		s.clientSecret = clientSecret
+	}
+}
+
+// WithClock replaces the clock used for transitions and timestamps
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// Server is a fake Aura API.
+// It implements the token endpoint as well as the instance, snapshot, pause/resume and project endpoints.
+type Server struct {
+	mux        *http.ServeMux
+	httpServer *httptest.Server
+
+	delay                  time.Duration
+	delays                 map[auraclient.InstanceDataStatus]time.Duration
+	latency                time.Duration
+	clientID, clientSecret string
+	now                    func() time.Time
+
+	mu        sync.Mutex
+	seq       int
+	tokens    map[string]time.Time
+	projects  map[string]Project
+	instances map[string]*instance
+	failures  []*Failure
+	requests  map[string]int
+}
+
+type instance struct {
+	auraclient.Instance
+	seq      int
+	username string
+	password string
+
+	next      auraclient.InstanceDataStatus
+	nextAt    time.Time
+	snapshots []*snapshot
+}
+
+type snapshot struct {
+	InstanceID string `json:"instance_id"`
+	Profile    string `json:"profile"`
+	SnapshotID string `json:"snapshot_id"`
+	Status     string `json:"status"`
+	Timestamp  string `json:"timestamp"`
+	readyAt    time.Time
+}
+
+type errorDetail struct {
+	Message string `json:"message"`
+	Reason  string `json:"reason"`
+	Field   string `json:"field,omitempty"`
+}
+
+type errorResponse struct {
+	Errors []errorDetail `json:"errors"`
+}
+
+// New returns a fake Aura API handler which is not listening.
+// Use it with an http.Server to run the fake API in a separate process.
+func New(opts ...Option) *Server {
+	s := &Server{
+		mux:       http.NewServeMux(),
+		delays:    make(map[auraclient.InstanceDataStatus]time.Duration),
+		now:       time.Now,
+		tokens:    make(map[string]time.Time),
+		projects:  make(map[string]Project),
+		instances: make(map[string]*instance),
+		requests:  make(map[string]int),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.mux.HandleFunc("POST "+TokenPath, s.token)
+	s.mux.HandleFunc("GET "+APIPrefix+"/tenants", s.listProjects)
+	s.mux.HandleFunc("GET "+APIPrefix+"/tenants/{tenantId}", s.getProject)
+	s.mux.HandleFunc("GET "+APIPrefix+"/tenants/{tenantId}/metrics-integration", s.getMetricsIntegration)
+	s.mux.HandleFunc("GET "+APIPrefix+"/instances", s.listInstances)
+	s.mux.HandleFunc("POST "+APIPrefix+"/instances", s.createInstance)
+	s.mux.HandleFunc("GET "+APIPrefix+"/instances/{instanceId}", s.getInstance)
+	s.mux.HandleFunc("PATCH "+APIPrefix+"/instances/{instanceId}", s.patchInstance)
+	s.mux.HandleFunc("DELETE "+APIPrefix+"/instances/{instanceId}", s.deleteInstance)
+	s.mux.HandleFunc("POST "+APIPrefix+"/instances/{instanceId}/pause", s.pauseInstance)
+	s.mux.HandleFunc("POST "+APIPrefix+"/instances/{instanceId}/resume", s.resumeInstance)
+	s.mux.HandleFunc("POST "+APIPrefix+"/instances/{instanceId}/upgrade", s.upgradeInstance)
+	s.mux.HandleFunc("GET "+APIPrefix+"/instances/{instanceId}/snapshots", s.listSnapshots)
+	s.mux.HandleFunc("POST "+APIPrefix+"/instances/{instanceId}/snapshots", s.createSnapshot)
+	s.mux.HandleFunc("GET "+APIPrefix+"/instances/{instanceId}/snapshots/{snapshotId}", s.getSnapshot)
+	s.mux.HandleFunc("POST "+APIPrefix+"/instances/{instanceId}/snapshots/{snapshotId}/restore", s.restoreSnapshot)
+
+	return s
+}
+
+// NewServer starts a fake Aura API listening on a local port. It needs to be closed once it is not used anymore.
+func NewServer(opts ...Option) *Server {
+	s := New(opts...)
+	s.httpServer = httptest.NewServer(s)
+	return s
+}
+
+// Close shuts down the server started by NewServer
+func (s *Server) Close() {
+	if s.httpServer != nil {
+		s.httpServer.Close()
+	}
+}
+
+// URL returns the root URL of the server started by NewServer
+func (s *Server) URL() string {
+	if s.httpServer == nil {
+		return ""
+	}
+
+	return s.httpServer.URL
+}
+
+// BaseURL returns the URL the Aura client needs to be configured with
+func (s *Server) BaseURL() string {
+	return s.URL() + APIPrefix
+}
+
+// TokenURL returns the URL of the OAuth2 token endpoint
+func (s *Server) TokenURL() string {
+	return s.URL() + TokenPath
+}
+
+// Client returns an HTTP client for the server started by NewServer
+func (s *Server) Client() *http.Client {
+	if s.httpServer == nil {
+		return http.DefaultClient
+	}
+
+	return s.httpServer.Client()
+}
+
+// AddProject registers a project instances can be created in
+func (s *Server) AddProject(id, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.projects[id] = Project{ID: id, Name: name}
+}
+
+// Instance returns an instance as it is currently returned by the API
+func (s *Server) Instance(id string) (auraclient.Instance, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inst, ok := s.instance(id)
+	if !ok {
+		return auraclient.Instance{}, false
+	}
+
+	return inst.Instance, true
+}
+
+// Credentials returns the initial credentials which were returned when the instance was created
+func (s *Server) Credentials(id string) (username, password string, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inst, ok := s.instance(id)
+	if !ok {
+		return "", "", false
+	}
+
+	return inst.username, inst.password, true
+}
+
+// SetInstanceStatus changes the status of an instance and cancels a pending transition,
+// e.g. to simulate an instance which was paused or suspended outside of the controller.
+func (s *Server) SetInstanceStatus(id string, status auraclient.InstanceDataStatus) error {
+	return s.UpdateInstance(id, func(inst *auraclient.Instance) {
+		inst.Data.Status = status
+	})
+}
+
+// UpdateInstance modifies an instance outside of the API, e.g. to simulate changes made in the Aura console.
+// A pending transition is cancelled.
+func (s *Server) UpdateInstance(id string, update func(*auraclient.Instance)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inst, ok := s.instance(id)
+	if !ok {
+		return fmt.Errorf("instance %s not found", id)
+	}
+
+	inst.next = ""
+	update(&inst.Instance)
+	return nil
+}
+
+// Fail returns the given failure for matching requests
+func (s *Server) Fail(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f.StatusCode == 0 {
+		f.StatusCode = http.StatusInternalServerError
+	}
+
+	s.failures = append(s.failures, &f)
+}
+
+// ClearFailures removes all failures
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = nil
+}
+
+// Requests returns the number of requests received for an endpoint, including failed requests.
+// The endpoint is a path template as listed in auraclient.Endpoints or TokenPath.
+func (s *Server) Requests(method, endpoint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[method+" "+endpoint]
+}
+
+// ServeHTTP handles a request to the fake Aura API
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.latency > 0 {
+		time.Sleep(s.latency)
+	}
+
+	_, pattern := s.mux.Handler(r)
+	endpoint := endpointOf(pattern)
+
+	if f := s.record(r.Method, endpoint); f != nil {
+		body := f.Body
+		if body == "" {
+			body = errorBody("injected-failure", fmt.Sprintf("injected failure for %s %s", r.Method, endpoint))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(f.StatusCode)
+		_, _ = w.Write([]byte(body))
+		return
+	}
+
+	if pattern != "" && endpoint != TokenPath && !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Invalid or expired access token", "")
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// endpointOf converts a mux pattern like "GET /v1/instances/{instanceId}" to the endpoint /instances/{instanceId}
+func endpointOf(pattern string) string {
+	if pattern == "" {
+		return "other"
+	}
+
+	_, path, _ := strings.Cut(pattern, " ")
+	return strings.TrimPrefix(path, APIPrefix)
+}
+
+// record counts the request and returns the failure it needs to be answered with, if any
+func (s *Server) record(method, endpoint string) *Failure {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests[method+" "+endpoint]++
+
+	for i, f := range s.failures {
+		if (f.Method != "" && f.Method != method) || (f.Endpoint != "" && f.Endpoint != endpoint) {
+			continue
+		}
+
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+		}
+
+		failure := *f
+		return &failure
+	}
+
+	return nil
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt, ok := s.tokens[token]
+	return ok && s.now().Before(expiresAt)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	if clientID == "" || (s.clientID != "" && (clientID != s.clientID || clientSecret != s.clientSecret)) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	token := randomHex(16)
+	s.mu.Lock()
+	s.tokens[token] = s.now().Add(tokenTTL)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": token,
+		"token_type":   "bearer",
+		"expires_in":   int(tokenTTL.Seconds()),
+	})
+}
+
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	projects := []auraclient.ProjectSummary{}
+	for _, p := range s.projects {
+		projects = append(projects, auraclient.ProjectSummary{Id: p.ID, Name: p.Name})
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].Id < projects[j].Id
+	})
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": projects})
+}
+
+func (s *Server) getProject(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.projects[r.PathValue("tenantId")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "tenant-not-found", "Tenant not found", "")
+		return
+	}
+
+	var project auraclient.Project
+	project.Data.Id = p.ID
+	project.Data.Name = p.Name
+	writeJSON(w, http.StatusOK, project)
+}
+
+func (s *Server) getMetricsIntegration(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.projects[r.PathValue("tenantId")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "tenant-not-found", "Tenant not found", "")
+		return
+	}
+
+	var details auraclient.MetricsIntegrationDetails
+	details.Data.Endpoint = fmt.Sprintf("https://customer-metrics-api.neo4j.io/api/v1/%s/metrics", p.ID)
+	writeJSON(w, http.StatusOK, details)
+}
+
+func (s *Server) listInstances(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenantID := r.URL.Query().Get("tenantId")
+	if _, ok := s.projects[tenantID]; tenantID != "" && !ok {
+		writeError(w, http.StatusNotFound, "tenant-not-found", "Tenant not found", "")
+		return
+	}
+
+	var matching []*instance
+	for id := range s.instances {
+		inst, ok := s.instance(id)
+		if ok && (tenantID == "" || inst.Data.TenantId == tenantID) {
+			matching = append(matching, inst)
+		}
+	}
+
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].seq < matching[j].seq
+	})
+
+	instances := []auraclient.InstanceSummary{}
+	for _, inst := range matching {
+		instances = append(instances, auraclient.InstanceSummary{
+			Id:            inst.Data.Id,
+			Name:          inst.Data.Name,
+			TenantId:      inst.Data.TenantId,
+			CloudProvider: auraclient.InstanceSummaryCloudProvider(inst.Data.CloudProvider),
+			CreatedAt:     inst.Data.CreatedAt,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": instances})
+}
+
+func (s *Server) createInstance(w http.ResponseWriter, r *http.Request) {
+	var req auraclient.PostInstancesJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid-request", fmt.Sprintf("Invalid request body: %s", err), "")
+		return
+	}
+
+	for field, value := range map[string]string{
+		"name":           req.Name,
+		"tenant_id":      req.TenantId,
+		"type":           string(req.Type),
+		"cloud_provider": string(req.CloudProvider),
+	} {
+		if value == "" {
+			writeError(w, http.StatusBadRequest, "invalid-request", fmt.Sprintf("%s is required", field), field)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.projects[req.TenantId]; !ok {
+		writeError(w, http.StatusNotFound, "tenant-not-found", "Tenant not found", "")
+		return
+	}
+
+	s.seq++
+	inst := &instance{
+		seq:      s.seq,
+		username: "neo4j",
+		password: randomHex(16),
+	}
+
+	memory := req.Memory
+	if memory == "" {
+		memory = "1GB"
+	}
+
+	inst.Data.Id = randomHex(4)
+	inst.Data.Name = req.Name
+	inst.Data.TenantId = req.TenantId
+	inst.Data.CloudProvider = req.CloudProvider
+	inst.Data.Region = req.Region
+	inst.Data.Type = req.Type
+	inst.Data.Memory = memory
+	inst.Data.Storage = storageOf(memory)
+	inst.Data.VectorOptimized = req.VectorOptimized
+	inst.Data.GraphAnalyticsPlugin = req.GraphAnalyticsPlugin
+	inst.Data.ConnectionUrl = fmt.Sprintf("neo4j+s://%s.databases.neo4j.io", inst.Data.Id)
+	inst.Data.CreatedAt = s.now().UTC().Format(time.RFC3339)
+
+	if req.Type == auraclient.InstanceTypeFreeDb {
+		zero := "0"
+		inst.Data.GraphNodes = &zero
+		inst.Data.GraphRelationships = &zero
+	}
+
+	s.transition(inst, auraclient.InstanceDataStatusCreating, auraclient.InstanceDataStatusRunning)
+	s.instances[inst.Data.Id] = inst
+
+	writeJSON(w, http.StatusAccepted, map[string]any{
+		"data": map[string]any{
+			"id":                     inst.Data.Id,
+			"name":                   inst.Data.Name,
+			"tenant_id":              inst.Data.TenantId,
+			"cloud_provider":         inst.Data.CloudProvider,
+			"region":                 inst.Data.Region,
+			"type":                   inst.Data.Type,
+			"connection_url":         inst.Data.ConnectionUrl,
+			"username":               inst.username,
+			"password":               inst.password,
+			"created_at":             inst.Data.CreatedAt,
+			"graph_analytics_plugin": inst.Data.GraphAnalyticsPlugin,
+			"vector_optimized":       inst.Data.VectorOptimized,
+		},
+	})
+}
+
+func (s *Server) getInstance(w http.ResponseWriter, r *http.Request) {
+	s.withInstance(w, r, func(inst *instance) {
+		writeJSON(w, http.StatusOK, inst.Instance)
+	})
+}
+
+func (s *Server) patchInstance(w http.ResponseWriter, r *http.Request) {
+	var req auraclient.PatchInstanceIdJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid-request", fmt.Sprintf("Invalid request body: %s", err), "")
+		return
+	}
+
+	s.withInstance(w, r, func(inst *instance) {
+		if req.Name != nil {
+			inst.Data.Name = *req.Name
+		}
+
+		if req.VectorOptimized != nil {
+			inst.Data.VectorOptimized = req.VectorOptimized
+		}
+
+		if req.GraphAnalyticsPlugin != nil {
+			inst.Data.GraphAnalyticsPlugin = req.GraphAnalyticsPlugin
+		}
+
+		// Only a rename is applied without changing the instance
+		if req.Memory != nil || req.Storage != nil {
+			if !s.requireStatus(w, inst, auraclient.InstanceDataStatusRunning) {
+				return
+			}
+
+			if req.Memory != nil {
+				inst.Data.Memory = *req.Memory
+				inst.Data.Storage = storageOf(*req.Memory)
+			}
+
+			if req.Storage != nil {
+				inst.Data.Storage = *req.Storage
+			}
+
+			s.transition(inst, auraclient.InstanceDataStatusUpdating, auraclient.InstanceDataStatusRunning)
+		}
+
+		writeJSON(w, http.StatusOK, inst.Instance)
+	})
+}
+
+func (s *Server) deleteInstance(w http.ResponseWriter, r *http.Request) {
+	s.withInstance(w, r, func(inst *instance) {
+		if inst.Data.Status == auraclient.InstanceDataStatusDestroying {
+			writeError(w, http.StatusConflict, "conflict", "Instance is already being destroyed", "")
+			return
+		}
+
+		s.transition(inst, auraclient.InstanceDataStatusDestroying, statusDestroyed)
+		writeJSON(w, http.StatusAccepted, inst.Instance)
+	})
+}
+
+func (s *Server) pauseInstance(w http.ResponseWriter, r *http.Request) {
+	s.withInstance(w, r, func(inst *instance) {
+		if !s.requireStatus(w, inst, auraclient.InstanceDataStatusRunning) {
+			return
+		}
+
+		s.transition(inst, auraclient.InstanceDataStatusPausing, auraclient.InstanceDataStatusPaused)
+		writeJSON(w, http.StatusAccepted, inst.Instance)
+	})
+}
+
+func (s *Server) resumeInstance(w http.ResponseWriter, r *http.Request) {
+	s.withInstance(w, r, func(inst *instance) {
+		if !s.requireStatus(w, inst, auraclient.InstanceDataStatusPaused) {
+			return
+		}
+
+		s.transition(inst, auraclient.InstanceDataStatusResuming, auraclient.InstanceDataStatusRunning)
+		writeJSON(w, http.StatusAccepted, inst.Instance)
+	})
+}
+
+func (s *Server) upgradeInstance(w http.ResponseWriter, r *http.Request) {
+	var req auraclient.PostUpgradeInstanceJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid-request", fmt.Sprintf("Invalid request body: %s", err), "")
+		return
+	}
+
+	s.withInstance(w, r, func(inst *instance) {
+		if inst.Data.Type != auraclient.InstanceTypeFreeDb {
+			writeError(w, http.StatusBadRequest, "invalid-request", "Only free instances can be upgraded", "")
+			return
+		}
+
+		if !s.requireStatus(w, inst, auraclient.InstanceDataStatusRunning) {
+			return
+		}
+
+		inst.Data.Type = auraclient.InstanceTypeProfessionalDb
+		inst.Data.GraphNodes = nil
+		inst.Data.GraphRelationships = nil
+		if req.Memory != nil {
+			inst.Data.Memory = *req.Memory
+			inst.Data.Storage = storageOf(*req.Memory)
+		}
+
+		if req.Storage != nil {
+			inst.Data.Storage = *req.Storage
+		}
+
+		s.transition(inst, auraclient.InstanceDataStatusUpdating, auraclient.InstanceDataStatusRunning)
+		writeJSON(w, http.StatusOK, inst.Instance)
+	})
+}
+
+func (s *Server) listSnapshots(w http.ResponseWriter, r *http.Request) {
+	s.withInstance(w, r, func(inst *instance) {
+		snapshots := []snapshot{}
+		for _, snap := range inst.snapshots {
+			snapshots = append(snapshots, *s.snapshot(snap))
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{"data": snapshots})
+	})
+}
+
+func (s *Server) createSnapshot(w http.ResponseWriter, r *http.Request) {
+	s.withInstance(w, r, func(inst *instance) {
+		if !s.requireStatus(w, inst, auraclient.InstanceDataStatusRunning) {
+			return
+		}
+
+		now := s.now()
+		snap := &snapshot{
+			InstanceID: inst.Data.Id,
+			Profile:    "AdHoc",
+			SnapshotID: randomHex(8),
+			Status:     SnapshotInProgress,
+			Timestamp:  now.UTC().Format(time.RFC3339),
+			readyAt:    now.Add(s.delay),
+		}
+
+		inst.snapshots = append(inst.snapshots, snap)
+		writeJSON(w, http.StatusAccepted, map[string]any{
+			"data": map[string]string{"snapshot_id": snap.SnapshotID},
+		})
+	})
+}
+
+func (s *Server) getSnapshot(w http.ResponseWriter, r *http.Request) {
+	s.withSnapshot(w, r, func(_ *instance, snap *snapshot) {
+		writeJSON(w, http.StatusOK, map[string]any{"data": snap})
+	})
+}
+
+func (s *Server) restoreSnapshot(w http.ResponseWriter, r *http.Request) {
+	s.withSnapshot(w, r, func(inst *instance, snap *snapshot) {
+		if snap.Status != SnapshotCompleted {
+			writeError(w, http.StatusConflict, "conflict", fmt.Sprintf("Snapshot is not completed: %s", snap.Status), "")
+			return
+		}
+
+		if !s.requireStatus(w, inst, auraclient.InstanceDataStatusRunning, auraclient.InstanceDataStatusLoadingFailed) {
+			return
+		}
+
+		s.transition(inst, auraclient.InstanceDataStatusRestoring, auraclient.InstanceDataStatusRunning)
+		writeJSON(w, http.StatusAccepted, inst.Instance)
+	})
+}
+
+// withInstance calls handle with the instance of the request while holding the lock
+func (s *Server) withInstance(w http.ResponseWriter, r *http.Request, handle func(*instance)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inst, ok := s.instance(r.PathValue("instanceId"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "instance-not-found", "Instance not found", "")
+		return
+	}
+
+	handle(inst)
+}
+
+// withSnapshot calls handle with the instance and snapshot of the request while holding the lock
+func (s *Server) withSnapshot(w http.ResponseWriter, r *http.Request, handle func(*instance, *snapshot)) {
+	s.withInstance(w, r, func(inst *instance) {
+		for _, snap := range inst.snapshots {
+			if snap.SnapshotID == r.PathValue("snapshotId") {
+				handle(inst, s.snapshot(snap))
+				return
+			}
+		}
+
+		writeError(w, http.StatusNotFound, "snapshot-not-found", "Snapshot not found", "")
+	})
+}
+
+// instance returns an instance after completing its pending transition, destroyed instances are removed
+func (s *Server) instance(id string) (*instance, bool) {
+	inst, ok := s.instances[id]
+	if !ok {
+		return nil, false
+	}
+
+	if inst.next != "" && !s.now().Before(inst.nextAt) {
+		inst.Data.Status = inst.next
+		inst.next = ""
+	}
+
+	if inst.Data.Status == statusDestroyed {
+		delete(s.instances, id)
+		return nil, false
+	}
+
+	return inst, true
+}
+
+// snapshot completes a snapshot once its delay has passed
+func (s *Server) snapshot(snap *snapshot) *snapshot {
+	if snap.Status == SnapshotInProgress && !s.now().Before(snap.readyAt) {
+		snap.Status = SnapshotCompleted
+	}
+
+	return snap
+}
+
+// transition moves an instance into a transient status which is left for the next status once the delay has passed
+func (s *Server) transition(inst *instance, transient, next auraclient.InstanceDataStatus) {
+	delay, ok := s.delays[transient]
+	if !ok {
+		delay = s.delay
+	}
+
+	inst.Data.Status = transient
+	inst.next = next
+	inst.nextAt = s.now().Add(delay)
+}
+
+// requireStatus writes a conflict if the instance is not in one of the given states
+func (s *Server) requireStatus(w http.ResponseWriter, inst *instance, states ...auraclient.InstanceDataStatus) bool {
+	for _, status := range states {
+		if inst.Data.Status == status {
+			return true
+		}
+	}
+
+	writeError(w, http.StatusConflict, "conflict", fmt.Sprintf("Operation is not allowed while the instance is %s", inst.Data.Status), "")
+	return false
+}
+
+// storageOf returns the storage Aura assigns to an instance with the given memory
+func storageOf(memory string) string {
+	gb, err := strconv.Atoi(strings.TrimSuffix(memory, "GB"))
+	if err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%dGB", gb*2)
+}
+
+func errorBody(reason, message string) string {
+	b, _ := json.Marshal(errorResponse{Errors: []errorDetail{{Message: message, Reason: reason}}})
+	return string(b)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, reason, message, field string) {
+	writeJSON(w, statusCode, errorResponse{Errors: []errorDetail{{Message: message, Reason: reason, Field: field}}})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(b)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
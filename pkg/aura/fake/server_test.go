@@ -0,0 +1,318 @@
+package fake
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	auraclient "github.com/doodlescheduling/neo4j-aura-controller/pkg/aura/client"
+	"github.com/tj/assert"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newClient(t *testing.T, s *Server) *auraclient.ClientWithResponses {
+	conf := clientcredentials.Config{
+		ClientID:     "id",
+		ClientSecret: "secret",
+		TokenURL:     s.TokenURL(),
+	}
+
+	c, err := auraclient.NewClientWithResponses(s.BaseURL(), auraclient.WithHTTPClient(conf.Client(context.Background())))
+	assert.NoError(t, err)
+	return c
+}
+
+func createInstance(t *testing.T, c *auraclient.ClientWithResponses, memory string) string {
+	res, err := c.PostInstancesWithResponse(context.Background(), auraclient.PostInstancesJSONRequestBody{
+		Name:          "instance",
+		TenantId:      "project",
+		Type:          auraclient.InstanceTypeProfessionalDb,
+		CloudProvider: auraclient.CloudProviderGcp,
+		Region:        "europe-west1",
+		Memory:        memory,
+		Version:       "5",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, res.StatusCode())
+	assert.Equal(t, "neo4j", res.JSON202.Data.Username)
+	assert.NotEmpty(t, res.JSON202.Data.Password)
+
+	return res.JSON202.Data.Id
+}
+
+func getStatus(t *testing.T, c *auraclient.ClientWithResponses, id string) auraclient.InstanceDataStatus {
+	res, err := c.GetInstanceIdWithResponse(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode())
+	return res.JSON200.Data.Status
+}
+
+func TestInstanceLifecycle(t *testing.T) {
+	ctx := context.Background()
+	clk := &clock{now: time.Now()}
+	s := NewServer(WithTransitionDelay(time.Minute), WithStatusDelay(auraclient.InstanceDataStatusPausing, time.Second), WithClock(clk.Now))
+	defer s.Close()
+	s.AddProject("project", "Project")
+	c := newClient(t, s)
+
+	id := createInstance(t, c, "4GB")
+	assert.Equal(t, auraclient.InstanceDataStatusCreating, getStatus(t, c, id))
+
+	list, err := c.GetInstancesWithResponse(ctx, &auraclient.GetInstancesParams{TenantId: ptr("project")})
+	assert.NoError(t, err)
+	assert.Len(t, list.JSON200.Data, 1)
+	assert.Equal(t, id, list.JSON200.Data[0].Id)
+
+	clk.Advance(time.Minute)
+	res, err := c.GetInstanceIdWithResponse(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, auraclient.InstanceDataStatusRunning, res.JSON200.Data.Status)
+	assert.Equal(t, "4GB", res.JSON200.Data.Memory)
+	assert.Equal(t, "8GB", res.JSON200.Data.Storage)
+	assert.Equal(t, "neo4j+s://"+id+".databases.neo4j.io", res.JSON200.Data.ConnectionUrl)
+
+	pause, err := c.PostPauseInstanceWithResponse(ctx, id, auraclient.PostPauseInstanceJSONRequestBody{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, pause.StatusCode())
+	assert.Equal(t, auraclient.InstanceDataStatusPausing, getStatus(t, c, id))
+	clk.Advance(time.Second)
+	assert.Equal(t, auraclient.InstanceDataStatusPaused, getStatus(t, c, id))
+
+	memory := "8GB"
+	patch, err := c.PatchInstanceIdWithResponse(ctx, id, auraclient.PatchInstanceIdJSONRequestBody{Memory: &memory})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, patch.StatusCode())
+
+	resume, err := c.PostResumeInstanceWithResponse(ctx, id, auraclient.PostResumeInstanceJSONRequestBody{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, resume.StatusCode())
+	assert.Equal(t, auraclient.InstanceDataStatusResuming, getStatus(t, c, id))
+	clk.Advance(time.Minute)
+
+	patch, err = c.PatchInstanceIdWithResponse(ctx, id, auraclient.PatchInstanceIdJSONRequestBody{Memory: &memory})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, patch.StatusCode())
+	assert.Equal(t, auraclient.InstanceDataStatusUpdating, patch.JSON200.Data.Status)
+	assert.Equal(t, "16GB", patch.JSON200.Data.Storage)
+	clk.Advance(time.Minute)
+	assert.Equal(t, auraclient.InstanceDataStatusRunning, getStatus(t, c, id))
+
+	del, err := c.DeleteInstanceIdWithResponse(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, del.StatusCode())
+	assert.Equal(t, auraclient.InstanceDataStatusDestroying, getStatus(t, c, id))
+	clk.Advance(time.Minute)
+
+	res, err = c.GetInstanceIdWithResponse(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode())
+	_, ok := s.Instance(id)
+	assert.False(t, ok)
+}
+
+func TestUpgrade(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+	s.AddProject("project", "Project")
+	c := newClient(t, s)
+
+	res, err := c.PostInstancesWithResponse(ctx, auraclient.PostInstancesJSONRequestBody{
+		Name:          "free",
+		TenantId:      "project",
+		Type:          auraclient.InstanceTypeFreeDb,
+		CloudProvider: auraclient.CloudProviderGcp,
+	})
+	assert.NoError(t, err)
+	id := res.JSON202.Data.Id
+
+	instance, ok := s.Instance(id)
+	assert.True(t, ok)
+	assert.Equal(t, "1GB", instance.Data.Memory)
+	assert.Equal(t, "0", *instance.Data.GraphNodes)
+
+	memory := "2GB"
+	upgrade, err := c.PostUpgradeInstanceWithResponse(ctx, id, auraclient.PostUpgradeInstanceJSONRequestBody{Memory: &memory})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, upgrade.StatusCode())
+	assert.Equal(t, auraclient.InstanceTypeProfessionalDb, upgrade.JSON200.Data.Type)
+	assert.Equal(t, "2GB", upgrade.JSON200.Data.Memory)
+	assert.Nil(t, upgrade.JSON200.Data.GraphNodes)
+
+	upgrade, err = c.PostUpgradeInstanceWithResponse(ctx, id, auraclient.PostUpgradeInstanceJSONRequestBody{Memory: &memory})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, upgrade.StatusCode())
+}
+
+func TestSnapshots(t *testing.T) {
+	ctx := context.Background()
+	clk := &clock{now: time.Now()}
+	s := NewServer(WithTransitionDelay(time.Minute), WithClock(clk.Now))
+	defer s.Close()
+	s.AddProject("project", "Project")
+	c := newClient(t, s)
+
+	id := createInstance(t, c, "4GB")
+	created, err := c.PostSnapshotsWithResponse(ctx, id, auraclient.PostSnapshotsJSONRequestBody{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, created.StatusCode())
+
+	clk.Advance(time.Minute)
+	created, err = c.PostSnapshotsWithResponse(ctx, id, auraclient.PostSnapshotsJSONRequestBody{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, created.StatusCode())
+	snapshotID := created.JSON202.Data.SnapshotId
+
+	restore, err := c.PostRestoreSnapshotWithResponse(ctx, id, snapshotID, auraclient.PostRestoreSnapshotJSONRequestBody{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, restore.StatusCode())
+
+	clk.Advance(time.Minute)
+	snapshot, err := c.GetSnapshotSnapshotidWithResponse(ctx, id, snapshotID)
+	assert.NoError(t, err)
+	assert.Equal(t, SnapshotCompleted, string(*snapshot.JSON200.Data.Status))
+
+	snapshots, err := c.GetSnapshotsWithResponse(ctx, id, nil)
+	assert.NoError(t, err)
+	assert.Len(t, *snapshots.JSON200.Data, 1)
+
+	restore, err = c.PostRestoreSnapshotWithResponse(ctx, id, snapshotID, auraclient.PostRestoreSnapshotJSONRequestBody{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, restore.StatusCode())
+	assert.Equal(t, auraclient.InstanceDataStatusRestoring, getStatus(t, c, id))
+
+	missing, err := c.GetSnapshotSnapshotidWithResponse(ctx, id, "missing")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, missing.StatusCode())
+}
+
+func TestProjects(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+	s.AddProject("b", "Project B")
+	s.AddProject("a", "Project A")
+	c := newClient(t, s)
+
+	projects, err := c.GetProjectsWithResponse(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []auraclient.ProjectSummary{{Id: "a", Name: "Project A"}, {Id: "b", Name: "Project B"}}, projects.JSON200.Data)
+
+	project, err := c.GetProjectIdWithResponse(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "Project A", project.JSON200.Data.Name)
+
+	metrics, err := c.GetProjectMetricsIntegrationDetailsWithResponse(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://customer-metrics-api.neo4j.io/api/v1/a/metrics", metrics.JSON200.Data.Endpoint)
+
+	project, err = c.GetProjectIdWithResponse(ctx, "unknown")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, project.StatusCode())
+
+	list, err := c.GetInstancesWithResponse(ctx, &auraclient.GetInstancesParams{TenantId: ptr("unknown")})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, list.StatusCode())
+	assert.Equal(t, `{"errors":[{"message":"Tenant not found","reason":"tenant-not-found"}]}`, string(list.Body))
+}
+
+func TestAuthentication(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer(WithCredentials("id", "other"))
+	defer s.Close()
+
+	c, err := auraclient.NewClientWithResponses(s.BaseURL(), auraclient.WithHTTPClient(s.Client()))
+	assert.NoError(t, err)
+
+	res, err := c.GetProjectsWithResponse(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode())
+
+	_, err = newClient(t, s).GetProjectsWithResponse(ctx)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid_client")
+}
+
+func TestFailures(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+	s.AddProject("project", "Project")
+	c := newClient(t, s)
+	id := createInstance(t, c, "4GB")
+
+	s.Fail(Failure{
+		Method:   http.MethodGet,
+		Endpoint: "/instances/{instanceId}",
+		Times:    1,
+	})
+	s.Fail(Failure{
+		Endpoint:   "/instances/{instanceId}/pause",
+		StatusCode: http.StatusServiceUnavailable,
+		Body:       `{"error":"unavailable"}`,
+	})
+
+	res, err := c.GetInstanceIdWithResponse(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode())
+	assert.Equal(t, `{"errors":[{"message":"injected failure for GET /instances/{instanceId}","reason":"injected-failure"}]}`, string(res.Body))
+	assert.Equal(t, auraclient.InstanceDataStatusRunning, getStatus(t, c, id))
+
+	for range 2 {
+		pause, err := c.PostPauseInstanceWithResponse(ctx, id, auraclient.PostPauseInstanceJSONRequestBody{})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, pause.StatusCode())
+		assert.Equal(t, `{"error":"unavailable"}`, string(pause.Body))
+	}
+
+	s.ClearFailures()
+	pause, err := c.PostPauseInstanceWithResponse(ctx, id, auraclient.PostPauseInstanceJSONRequestBody{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, pause.StatusCode())
+
+	assert.Equal(t, 2, s.Requests(http.MethodGet, "/instances/{instanceId}"))
+	assert.Equal(t, 3, s.Requests(http.MethodPost, "/instances/{instanceId}/pause"))
+	assert.Equal(t, 1, s.Requests(http.MethodPost, TokenPath))
+}
+
+func TestSetInstanceStatus(t *testing.T) {
+	s := NewServer(WithTransitionDelay(time.Hour))
+	defer s.Close()
+	s.AddProject("project", "Project")
+	c := newClient(t, s)
+	id := createInstance(t, c, "4GB")
+
+	assert.NoError(t, s.SetInstanceStatus(id, auraclient.InstanceDataStatusSuspended))
+	assert.Equal(t, auraclient.InstanceDataStatusSuspended, getStatus(t, c, id))
+	assert.Error(t, s.SetInstanceStatus("missing", auraclient.InstanceDataStatusRunning))
+
+	assert.NoError(t, s.UpdateInstance(id, func(instance *auraclient.Instance) {
+		instance.Data.Memory = "2GB"
+	}))
+
+	instance, ok := s.Instance(id)
+	assert.True(t, ok)
+	assert.Equal(t, "2GB", instance.Data.Memory)
+
+	username, password, ok := s.Credentials(id)
+	assert.True(t, ok)
+	assert.Equal(t, "neo4j", username)
+	assert.Len(t, password, 32)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}